@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/simulator"
+)
+
+// ion-cannon-sim serves the /status and /fire API of an ion cannon, to run the service without the Docker images.
+func main() {
+	cfg, err := simulator.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3000"
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           simulator.New(cfg).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	log.Printf("ion cannon of generation %d listening on %s, fire time %s", cfg.Generation, srv.Addr, cfg.FireTime)
+	log.Fatal(srv.ListenAndServe())
+}
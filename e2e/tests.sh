@@ -22,10 +22,12 @@ API_ENDPOINT="http://localhost:3000"
                 -H "Content-Type: application/json" \
                 -X POST \
                 -d "$INPUT" \
-                "$API_ENDPOINT/attack"
+                "$API_ENDPOINT/v1/attack"
             )
 
-            OUTPUT=$(echo $OUTPUT_RAW | jq -r --sort-keys .)
+            # Only compare the fields present in the expected report
+            OUTPUT=$(echo $OUTPUT_RAW | jq -r --sort-keys --argjson expected "$EXPECTED" \
+                'with_entries(select(.key as $k | $expected | has($k)))')
 
             echo $EXPECTED;
             echo $OUTPUT;
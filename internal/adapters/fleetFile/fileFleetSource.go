@@ -0,0 +1,138 @@
+package fleetFile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// FileFleetSource reads the definitions of the ion cannons from a local YAML or JSON file.
+// The format is chosen by the extension of the file: ".json", ".yaml" or ".yml".
+type FileFleetSource struct {
+	path string
+}
+
+// NewFileFleetSource creates a new instance of the FileFleetSource.
+func NewFileFleetSource(path string) (*FileFleetSource, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return &FileFleetSource{path: path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported fleet file %s: must be a .json, .yaml or .yml file", path)
+	}
+}
+
+// Path returns the path of the fleet file.
+func (s *FileFleetSource) Path() string {
+	return s.path
+}
+
+// Load reads and decodes the fleet file.
+func (s *FileFleetSource) Load() ([]*domain.CannonDefinition, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record fleetRecord
+	if strings.ToLower(filepath.Ext(s.path)) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&record)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&record)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode fleet file %s: %w", s.path, err)
+	}
+
+	return record.toDomain(filepath.Dir(s.path)), nil
+}
+
+// fleetRecord is the format of the fleet file:
+//
+//	cannons:
+//	  - id: cannon-1
+//	    url: http://ion-cannon-1:3000
+//	    position: {x: 0, y: 0}
+//	    tags: [north]
+//	    tls: {ca: certs/ca.pem, cert: certs/endor.pem, key: certs/endor-key.pem, serverName: ion-cannon-1}
+//	    secret: {file: secrets/cannon-1}
+type fleetRecord struct {
+	Cannons []cannonRecord `json:"cannons" yaml:"cannons"`
+}
+
+type cannonRecord struct {
+	ID       string          `json:"id" yaml:"id"`
+	URL      string          `json:"url" yaml:"url"`
+	Position *positionRecord `json:"position,omitempty" yaml:"position,omitempty"`
+	Tags     []string        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	TLS      *tlsRecord      `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Secret   *secretRecord   `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// tlsRecord holds the paths of the TLS files of a cannon, relative paths are resolved from the fleet file.
+type tlsRecord struct {
+	CA         string `json:"ca,omitempty" yaml:"ca,omitempty"`
+	Cert       string `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key        string `json:"key,omitempty" yaml:"key,omitempty"`
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+}
+
+// secretRecord locates the signing secret of a cannon in a file, relative to the fleet file, or an environment variable.
+type secretRecord struct {
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	Env  string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+type positionRecord struct {
+	X int `json:"x" yaml:"x"`
+	Y int `json:"y" yaml:"y"`
+}
+
+func (r fleetRecord) toDomain(dir string) []*domain.CannonDefinition {
+	definitions := make([]*domain.CannonDefinition, 0, len(r.Cannons))
+	for _, c := range r.Cannons {
+		definition := &domain.CannonDefinition{
+			ID:   c.ID,
+			URL:  c.URL,
+			Tags: c.Tags,
+		}
+		if definition.ID == "" {
+			definition.ID = c.URL
+		}
+		if c.Position != nil {
+			definition.Position = domain.NewCoordinates(c.Position.X, c.Position.Y)
+		}
+		if c.TLS != nil {
+			definition.TLS = &domain.CannonTLS{
+				CAFile:     resolvePath(dir, c.TLS.CA),
+				CertFile:   resolvePath(dir, c.TLS.Cert),
+				KeyFile:    resolvePath(dir, c.TLS.Key),
+				ServerName: c.TLS.ServerName,
+			}
+		}
+		if c.Secret != nil {
+			definition.Secret = &domain.CannonSecret{File: resolvePath(dir, c.Secret.File), Env: c.Secret.Env}
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// resolvePath resolves a relative path from dir, empty paths are kept empty.
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
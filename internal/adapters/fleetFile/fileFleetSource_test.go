@@ -0,0 +1,96 @@
+package fleetFile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFleetSource(t *testing.T) {
+	expected := func(dir string) []*domain.CannonDefinition {
+		return []*domain.CannonDefinition{
+			{ID: "cannon-1", URL: "http://ion-cannon-1:3000", Position: domain.NewCoordinates(10, 20), Tags: []string{"north"}},
+			{ID: "http://ion-cannon-2:3000", URL: "http://ion-cannon-2:3000"},
+			{ID: "cannon-3", URL: "https://ion-cannon-3:3443", TLS: &domain.CannonTLS{
+				CAFile:     "/etc/endor/ca.pem",
+				CertFile:   filepath.Join(dir, "certs/endor.pem"),
+				KeyFile:    filepath.Join(dir, "certs/endor-key.pem"),
+				ServerName: "ion-cannon",
+			}, Secret: &domain.CannonSecret{File: filepath.Join(dir, "secrets/cannon-3")}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{
+			name: "yaml",
+			file: "fleet.yaml",
+			data: `
+cannons:
+  - id: cannon-1
+    url: http://ion-cannon-1:3000
+    position: {x: 10, y: 20}
+    tags: [north]
+  - url: http://ion-cannon-2:3000
+  - id: cannon-3
+    url: https://ion-cannon-3:3443
+    tls:
+      ca: /etc/endor/ca.pem
+      cert: certs/endor.pem
+      key: certs/endor-key.pem
+      serverName: ion-cannon
+    secret: {file: secrets/cannon-3}
+`,
+		},
+		{
+			name: "json",
+			file: "fleet.json",
+			data: `{"cannons": [
+				{"id": "cannon-1", "url": "http://ion-cannon-1:3000", "position": {"x": 10, "y": 20}, "tags": ["north"]},
+				{"url": "http://ion-cannon-2:3000"},
+				{"id": "cannon-3", "url": "https://ion-cannon-3:3443", "tls": {
+					"ca": "/etc/endor/ca.pem", "cert": "certs/endor.pem", "key": "certs/endor-key.pem", "serverName": "ion-cannon"
+				}, "secret": {"file": "secrets/cannon-3"}}
+			]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.data), 0o644))
+
+			source, err := NewFileFleetSource(path)
+			assert.NoError(t, err)
+
+			definitions, err := source.Load()
+			assert.NoError(t, err)
+			assert.Equal(t, expected(dir), definitions)
+		})
+	}
+}
+
+func TestFileFleetSource_Errors(t *testing.T) {
+	_, err := NewFileFleetSource("fleet.toml")
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	source, err := NewFileFleetSource(filepath.Join(dir, "fleet.yml"))
+	assert.NoError(t, err)
+
+	_, err = source.Load()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Unknown fields are rejected to detect typos in the file
+	assert.NoError(t, os.WriteFile(source.Path(), []byte("cannons:\n  - id: cannon-1\n    uri: http://ion-cannon-1:3000\n"), 0o644))
+	_, err = source.Load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,8 @@
+package adapters
+
+import "github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+
+// FleetSource Interface for service to discover the ion cannons of the fleet
+type FleetSource interface {
+	Load() ([]*domain.CannonDefinition, error)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// AdminAuth rejects the requests without the bearer token of the administrators of the fleet.
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				render.Render(w, r, ErrInvalidRequest(errors.New("missing or invalid admin token"), http.StatusUnauthorized))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// listCannons is the HTTP handler for the "GET /admin/cannons" endpoint.
+func (h *HandlerHTTP) listCannons(w http.ResponseWriter, r *http.Request) {
+	cannons := h.svc.Fleet().List()
+
+	res := make([]*CannonResponse, 0, len(cannons))
+	for i := range cannons {
+		res = append(res, NewCannonResponse(&cannons[i]))
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, res)
+}
+
+// addCannon is the HTTP handler for the "POST /admin/cannons" endpoint.
+// The ion cannon must be reachable to be added to the fleet.
+func (h *HandlerHTTP) addCannon(w http.ResponseWriter, r *http.Request) {
+	data := &CannonRequest{}
+	if err := render.DecodeJSON(r.Body, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		return
+	}
+	if err := h.v.Struct(data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.svc.StatusTimeout())
+	defer cancel()
+	cannon, err := h.svc.Fleet().Add(ctx, data.ConvertToCannonDefinition())
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+url.PathEscape(cannon.ID))
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, NewCannonResponse(cannon))
+}
+
+// removeCannon is the HTTP handler for the "DELETE /admin/cannons/{cannonID}" endpoint.
+func (h *HandlerHTTP) removeCannon(w http.ResponseWriter, r *http.Request) {
+	id, err := cannonIDParam(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		return
+	}
+
+	if err := h.svc.Fleet().Remove(id); err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enableCannon is the HTTP handler for the "POST /admin/cannons/{cannonID}/enable" endpoint.
+func (h *HandlerHTTP) enableCannon(w http.ResponseWriter, r *http.Request) {
+	h.setCannonEnabled(w, r, true)
+}
+
+// disableCannon is the HTTP handler for the "POST /admin/cannons/{cannonID}/disable" endpoint.
+func (h *HandlerHTTP) disableCannon(w http.ResponseWriter, r *http.Request) {
+	h.setCannonEnabled(w, r, false)
+}
+
+func (h *HandlerHTTP) setCannonEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	id, err := cannonIDParam(r)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		return
+	}
+
+	cannon, err := h.svc.Fleet().SetEnabled(id, enabled)
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, NewCannonResponse(cannon))
+}
+
+// cannonIDParam returns the unescaped ion cannon ID of the path, as the IDs default to the URL of the cannons.
+func cannonIDParam(r *http.Request) (string, error) {
+	return url.PathUnescape(chi.URLParam(r, "cannonID"))
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Generation: 1, Available: true}, nil
+		},
+	}
+	svc := services.NewEndorService([]adapters.IonCannon{cannon})
+
+	send := func(router http.Handler, method string, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("not served on the public port", func(t *testing.T) {
+		s := NewHTTPServer(svc, validator.New())
+
+		for _, path := range []string{"/v1/admin/cannons", "/admin/cannons"} {
+			assert.Equal(t, http.StatusNotFound, send(s.h.r, http.MethodGet, path, nil).Code, path)
+		}
+		assert.Equal(t, http.StatusOK, send(s.h.admin, http.MethodGet, "/v1/admin/cannons", nil).Code)
+	})
+
+	t.Run("no CORS on the admin listener", func(t *testing.T) {
+		s := NewHTTPServer(svc, validator.New())
+		rec := send(s.h.admin, http.MethodOptions, "/v1/admin/cannons", http.Header{
+			"Origin":                        {"https://attacker.example"},
+			"Access-Control-Request-Method": {http.MethodPost},
+		})
+
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("requires the admin token when set", func(t *testing.T) {
+		s := NewHTTPServer(svc, validator.New(), WithAdminToken("s3cr3t"))
+
+		rec := send(s.h.admin, http.MethodPost, "/v1/admin/cannons/cannon-1/disable", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"unauthorized"`)
+		assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+
+		rec = send(s.h.admin, http.MethodGet, "/v1/admin/cannons", http.Header{"Authorization": {"Bearer wrong"}})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = send(s.h.admin, http.MethodGet, "/v1/admin/cannons", http.Header{"Authorization": {"Bearer s3cr3t"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assertValidResponse(t, s.h.spec, http.MethodGet, "/admin/cannons", rec)
+	})
+
+	t.Run("IDs with slashes escaped in the path", func(t *testing.T) {
+		byURL := &mocks.IonCannonClientMock{CannonID: "http://cannon-9:3000"}
+		svc := services.NewEndorService([]adapters.IonCannon{byURL})
+		s := NewHTTPServer(svc, validator.New())
+
+		rec := send(s.h.admin, http.MethodPost, "/v1/admin/cannons/http:%2F%2Fcannon-9:3000/disable", nil)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"id":"http://cannon-9:3000"`)
+
+		rec = send(s.h.admin, http.MethodDelete, "/v1/admin/cannons/http:%2F%2Fcannon-9:3000", nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Empty(t, svc.Fleet().List())
+	})
+
+	t.Run("rejects the TLS files and the secret in the body", func(t *testing.T) {
+		s := NewHTTPServer(svc, validator.New())
+
+		for property, body := range map[string]string{
+			"tls":    `{"id": "cannon-2", "url": "https://cannon-2:3443", "tls": {"ca": "/etc/shadow"}}`,
+			"secret": `{"id": "cannon-2", "url": "http://cannon-2:3000", "secret": {"env": "ADMIN_TOKEN"}}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/cannons", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			s.h.admin.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, property)
+			assert.Contains(t, rec.Body.String(), `property \"`+property+`\" is unsupported`)
+		}
+		assert.Len(t, svc.Fleet().List(), 1)
+	})
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+const (
+	// DefaultBatchConcurrency is the default number of items of a batch attacking concurrently.
+	DefaultBatchConcurrency = 4
+
+	// BatchMaxItems is the maximum number of items of a batch.
+	BatchMaxItems = 100
+
+	// ContentTypeNDJSON is the content type of the batches streamed with one JSON item per line.
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// DefaultBatchTimeout is the default maximum time a batch attacks its items, leaving time to write the
+	// results within the WriteTimeout. The items not finished by then fail with 504.
+	DefaultBatchTimeout = WriteTimeout - 2*time.Second
+
+	// batchMaxLineSize is the maximum size of a line of a streamed batch.
+	batchMaxLineSize = 1 << 20
+)
+
+// attackBatch is the HTTP handler for the "/attack/batch" endpoint.
+// It attacks the target of every radar of the batch, the items sharing the ion cannons of the fleet, and
+// returns the result of every item with its own status. A JSON array is answered with all the results,
+// while a stream of NDJSON items is answered with a stream of NDJSON results.
+// The items are attacked within the batch timeout, so the results are written before the WriteTimeout.
+func (h *HandlerHTTP) attackBatch(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.batchTimeout)
+	defer cancel()
+
+	if isNDJSON(r) {
+		h.attackBatchStream(ctx, w, r)
+		return
+	}
+
+	items := []*BatchAttackItem{}
+	if err := render.DecodeJSON(r.Body, &items); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		return
+	}
+	if len(items) > BatchMaxItems {
+		render.Render(w, r, ErrInvalidRequest(fmt.Errorf("a batch has at most %d items", BatchMaxItems), http.StatusBadRequest))
+		return
+	}
+
+	res := &BatchAttackResponse{Results: make([]*BatchAttackResult, len(items))}
+	sem := make(chan struct{}, h.batchConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		i, item := i, item
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			res.Results[i] = h.attackBatchItem(ctx, i, item)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range res.Results {
+		if result.Error == nil {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, res)
+}
+
+// attackBatchStream attacks the items of a NDJSON batch as they are read, every line being validated
+// on its own. The results are written and flushed one by one in the order they finish. HTTP/2 requests
+// get them while the batch is read, while HTTP/1 requests get them once the whole batch was read,
+// as their body cannot be read after the response has started.
+func (h *HandlerHTTP) attackBatchStream(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	results := make(chan *BatchAttackResult)
+	read := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		defer close(results)
+		defer wg.Wait()
+		defer close(read)
+
+		sem := make(chan struct{}, h.batchConcurrency)
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), batchMaxLineSize)
+		next := 0
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			index := next
+			next++
+			if index >= BatchMaxItems {
+				results <- batchErrorResult(index, "", ErrInvalidRequest(fmt.Errorf("a batch has at most %d items", BatchMaxItems), http.StatusBadRequest))
+				return
+			}
+
+			item, err := h.decodeBatchItem(line)
+			if err != nil {
+				results <- batchErrorResult(index, "", ErrInvalidRequest(err, http.StatusBadRequest))
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// The batch timed out, the remaining items are reported without being attacked
+				results <- batchErrorResult(index, item.ID, ErrResponseFor(ctx.Err()))
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				results <- h.attackBatchItem(ctx, index, item)
+			}()
+		}
+		if err := scanner.Err(); err != nil {
+			results <- batchErrorResult(next, "", ErrInvalidRequest(err, http.StatusBadRequest))
+		}
+	}()
+
+	// Keep the results finished while the batch is read
+	var pending []*BatchAttackResult
+	for reading := r.ProtoMajor < 2; reading; {
+		select {
+		case result := <-results:
+			pending = append(pending, result)
+		case <-read:
+			reading = false
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	write := func(result *BatchAttackResult) {
+		enc.Encode(result)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	for _, result := range pending {
+		write(result)
+	}
+	for result := range results {
+		write(result)
+	}
+}
+
+// decodeBatchItem decodes a line of a NDJSON batch and validates it with the schema of the OpenAPI spec.
+func (h *HandlerHTTP) decodeBatchItem(line []byte) (*BatchAttackItem, error) {
+	var value interface{}
+	if err := json.Unmarshal(line, &value); err != nil {
+		return nil, err
+	}
+	if err := h.spec.Components.Schemas["BatchAttackItem"].Value.VisitJSON(value); err != nil {
+		return nil, err
+	}
+
+	item := &BatchAttackItem{}
+	if err := json.Unmarshal(line, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// attackBatchItem attacks the target of an item of a batch. Items with an idempotency key get the report
+// stored for the same key and radar instead of firing again.
+func (h *HandlerHTTP) attackBatchItem(ctx context.Context, index int, item *BatchAttackItem) *BatchAttackResult {
+	if err := h.v.Struct(item); err != nil {
+		return batchErrorResult(index, item.ID, ErrInvalidRequest(err, http.StatusBadRequest))
+	}
+	attackData, err := item.Radar.ConvertToAttackDataModel()
+	if err != nil {
+		return batchErrorResult(index, item.ID, ErrInvalidRequest(err, http.StatusBadRequest))
+	}
+	if err := checkSyncWait(attackData); err != nil {
+		return batchErrorResult(index, item.ID, ErrInvalidRequest(err, http.StatusBadRequest))
+	}
+
+	attack := func() *BatchAttackResult {
+		// The items not started within the batch timeout are not attacked
+		if err := ctx.Err(); err != nil {
+			return batchErrorResult(index, item.ID, ErrResponseFor(err))
+		}
+		report, err := h.svc.Attack(ctx, attackData)
+		if err != nil {
+			return batchErrorResult(index, item.ID, ErrResponseFor(err))
+		}
+		return &BatchAttackResult{Index: index, ID: item.ID, Status: http.StatusOK, Report: NewAttackReportResponse(report)}
+	}
+	if item.IdempotencyKey == "" {
+		return attack()
+	}
+
+	// The key and the response are the ones of /attack, so an attack can be retried with either endpoint
+	body := item.rawRadar
+	if body == nil {
+		body, _ = json.Marshal(item.Radar)
+	}
+	var result *BatchAttackResult
+	stored, replayed, err := h.idempotency.Do(ctx, idempotencyKey(http.MethodPost, item.IdempotencyKey), idempotencyBody(body), func() *idempotentResponse {
+		result = attack()
+		var res bytes.Buffer
+		if result.Report != nil {
+			json.NewEncoder(&res).Encode(result.Report)
+		} else {
+			json.NewEncoder(&res).Encode(result.Error)
+		}
+		return &idempotentResponse{
+			status: result.Status,
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   res.Bytes(),
+			keep:   result.Error != nil && errors.Is(result.Error.Err, domain.ErrFireSent),
+		}
+	})
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		return batchErrorResult(index, item.ID, ErrResponseFor(err))
+	}
+	if err != nil {
+		return batchErrorResult(index, item.ID, ErrInvalidRequest(err, http.StatusRequestTimeout))
+	}
+	if !replayed {
+		return result
+	}
+
+	result = &BatchAttackResult{Index: index, ID: item.ID, Status: stored.status, Replayed: true}
+	if stored.status != http.StatusOK {
+		// The failure of an attack that may have fired
+		result.Error = &ErrResponse{HTTPStatusCode: stored.status}
+		if err := json.Unmarshal(stored.body, result.Error); err != nil {
+			return batchErrorResult(index, item.ID, ErrResponseFor(err))
+		}
+		return result
+	}
+	if err := json.Unmarshal(stored.body, &result.Report); err != nil {
+		return batchErrorResult(index, item.ID, ErrResponseFor(err))
+	}
+	return result
+}
+
+// batchErrorResult returns the result of an item of a batch that failed.
+func batchErrorResult(index int, id string, renderer render.Renderer) *BatchAttackResult {
+	errRes := renderer.(*ErrResponse)
+	return &BatchAttackResult{Index: index, ID: id, Status: errRes.HTTPStatusCode, Error: errRes}
+}
+
+// isNDJSON returns true if the request body is a stream of NDJSON items.
+func isNDJSON(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == ContentTypeNDJSON
+}
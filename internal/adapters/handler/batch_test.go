@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+// radarAt returns a radar with soldiers at the given coordinates.
+func radarAt(x, y int) string {
+	return fmt.Sprintf(`{"protocols":["closest-enemies"],"scan":[{"coordinates":{"x":%d,"y":%d},"enemies":{"type":"soldier","number":10}}]}`, x, y)
+}
+
+func TestAttackBatch(t *testing.T) {
+	var fired, running, maxRunning int32
+	cannons := []adapters.IonCannon{}
+	for i := 1; i <= 5; i++ {
+		cannons = append(cannons, &mocks.IonCannonClientMock{
+			CannonID: fmt.Sprintf("cannon-%d", i),
+			CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+				return &domain.IonCannon{Generation: 1, Available: true}, nil
+			},
+			FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (int, int, error) {
+				atomic.AddInt32(&fired, 1)
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				if targetX == 99 {
+					return 0, 0, errors.New("ion cannon jammed")
+				}
+				return enemies, 1, nil
+			},
+		})
+	}
+	s := NewHTTPServer(services.NewEndorService(cannons), validator.New(), WithBatchConcurrency(2))
+
+	send := func(contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/attack/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		s.h.r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("JSON array with individual results", func(t *testing.T) {
+		atomic.StoreInt32(&maxRunning, 0)
+		body := fmt.Sprintf(`[{"id":"droid-1","radar":%s},{"id":"droid-2","radar":%s},{"radar":%s},{"radar":%s}]`,
+			radarAt(0, 10), radarAt(99, 0), radarAt(0, 20), radarAt(0, 30))
+		rec := send("application/json", body)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assertValidResponse(t, s.h.spec, http.MethodPost, "/attack/batch", rec)
+		res := &BatchAttackResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+
+		assert.Equal(t, 3, res.Succeeded)
+		assert.Equal(t, 1, res.Failed)
+		require.Len(t, res.Results, 4)
+		for i, result := range res.Results {
+			assert.Equal(t, i, result.Index)
+		}
+		assert.Equal(t, "droid-1", res.Results[0].ID)
+		assert.Equal(t, 10, *res.Results[0].Report.Target.Y)
+		assert.Equal(t, http.StatusBadGateway, res.Results[1].Status)
+		assert.Equal(t, "cannon_fire_failed", res.Results[1].Error.Code)
+		assert.Equal(t, 30, *res.Results[3].Report.Target.Y)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+	})
+
+	t.Run("JSON array validated as a whole", func(t *testing.T) {
+		rec := send("application/json", fmt.Sprintf(`[{"radar":%s},{"radar":%s}]`, radarAt(0, 10), radarAt(-1, 10)))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("items with an idempotency key are replayed", func(t *testing.T) {
+		body := fmt.Sprintf(`[{"idempotencyKey":"key-1","radar":%s}]`, radarAt(0, 40))
+		send("application/json", body)
+		before := atomic.LoadInt32(&fired)
+		rec := send("application/json", body)
+
+		res := &BatchAttackResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, before, atomic.LoadInt32(&fired))
+		assert.True(t, res.Results[0].Replayed)
+		assert.Equal(t, 40, *res.Results[0].Report.Target.Y)
+
+		rec = send("application/json", fmt.Sprintf(`[{"idempotencyKey":"key-1","radar":%s}]`, radarAt(0, 50)))
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, http.StatusConflict, res.Results[0].Status)
+		assert.Equal(t, "idempotency_key_reused", res.Results[0].Error.Code)
+	})
+
+	t.Run("failures of items that may have fired are replayed", func(t *testing.T) {
+		body := fmt.Sprintf(`[{"idempotencyKey":"key-jammed","radar":%s}]`, radarAt(99, 0))
+		send("application/json", body)
+		before := atomic.LoadInt32(&fired)
+		rec := send("application/json", body)
+
+		res := &BatchAttackResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, before, atomic.LoadInt32(&fired))
+		assert.True(t, res.Results[0].Replayed)
+		assert.Equal(t, http.StatusBadGateway, res.Results[0].Status)
+		assert.Equal(t, "cannon_fire_failed", res.Results[0].Error.Code)
+		assert.Equal(t, 1, res.Failed)
+	})
+
+	t.Run("idempotency keys shared with /attack", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/attack", strings.NewReader(strings.ReplaceAll(radarAt(0, 60), ",", ", ")))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "key-2")
+		single := httptest.NewRecorder()
+		s.h.r.ServeHTTP(single, req)
+		require.Equal(t, http.StatusOK, single.Code, single.Body.String())
+		before := atomic.LoadInt32(&fired)
+
+		// The batch replays the report of /attack
+		rec := send("application/json", fmt.Sprintf(`[{"idempotencyKey":"key-2","radar":%s}]`, radarAt(0, 60)))
+		res := &BatchAttackResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, before, atomic.LoadInt32(&fired))
+		assert.True(t, res.Results[0].Replayed)
+		assert.Equal(t, 60, *res.Results[0].Report.Target.Y)
+
+		// And /attack replays the report of the batch
+		send("application/json", fmt.Sprintf(`[{"idempotencyKey":"key-3","radar":%s}]`, radarAt(0, 70)))
+		before = atomic.LoadInt32(&fired)
+		req = httptest.NewRequest(http.MethodPost, "/attack", strings.NewReader(radarAt(0, 70)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "key-3")
+		single = httptest.NewRecorder()
+		s.h.r.ServeHTTP(single, req)
+
+		assert.Equal(t, http.StatusOK, single.Code)
+		assert.Equal(t, "true", single.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, before, atomic.LoadInt32(&fired))
+		report := &AttackReportResponse{}
+		require.NoError(t, json.Unmarshal(single.Body.Bytes(), report))
+		assert.Equal(t, 70, *report.Target.Y)
+	})
+
+	t.Run("items not attacked within the batch timeout fail", func(t *testing.T) {
+		s := NewHTTPServer(services.NewEndorService(cannons), validator.New(), WithBatchTimeout(time.Nanosecond))
+		req := httptest.NewRequest(http.MethodPost, "/v1/attack/batch", strings.NewReader(fmt.Sprintf(`[{"radar":%s}]`, radarAt(0, 10))))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		s.h.r.ServeHTTP(rec, req)
+
+		res := &BatchAttackResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		assert.Equal(t, 1, res.Failed)
+		assert.Equal(t, http.StatusGatewayTimeout, res.Results[0].Status, rec.Body.String())
+	})
+
+	t.Run("NDJSON results streamed while the batch is sent over HTTP/2", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(s.h.r)
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		body, items := io.Pipe()
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/attack/batch", body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", ContentTypeNDJSON)
+		go fmt.Fprintf(items, "{\"id\":\"droid-1\",\"radar\":%s}\n", radarAt(0, 10))
+
+		client := server.Client()
+		client.Timeout = 5 * time.Second
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, 2, resp.ProtoMajor)
+		results := bufio.NewScanner(resp.Body)
+
+		// The result of the first item is received before the next one is sent
+		require.True(t, results.Scan())
+		assert.Contains(t, results.Text(), `"id":"droid-1"`)
+		fmt.Fprintf(items, "{\"id\":\"droid-2\",\"radar\":%s}\n", radarAt(0, 20))
+		items.Close()
+		require.True(t, results.Scan())
+		assert.Contains(t, results.Text(), `"id":"droid-2"`)
+		assert.False(t, results.Scan())
+	})
+
+	t.Run("NDJSON stream validated line by line", func(t *testing.T) {
+		body := strings.Join([]string{
+			fmt.Sprintf(`{"id":"droid-1","radar":%s}`, radarAt(0, 10)),
+			``,
+			fmt.Sprintf(`{"id":"droid-2","radar":%s}`, radarAt(-1, 10)),
+			`{"id":"droid-3",`,
+			fmt.Sprintf(`{"id":"droid-4","radar":%s}`, radarAt(0, 30)),
+		}, "\n")
+		rec := send(ContentTypeNDJSON, body)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, ContentTypeNDJSON, rec.Header().Get("Content-Type"))
+		results := []*BatchAttackResult{}
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			result := &BatchAttackResult{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), result))
+			results = append(results, result)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+		require.Len(t, results, 4)
+		assert.Equal(t, http.StatusOK, results[0].Status)
+		assert.Equal(t, "droid-1", results[0].ID)
+		assert.Equal(t, http.StatusBadRequest, results[1].Status)
+		assert.Contains(t, results[1].Error.ErrorText, "number must be at least 0")
+		assert.Equal(t, http.StatusBadRequest, results[2].Status)
+		assert.Equal(t, http.StatusOK, results[3].Status)
+		assert.Equal(t, 30, *results[3].Report.Target.Y)
+	})
+}
@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/render"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
 )
 
 // ErrResponse renderer type for handling all sorts of errors.
@@ -12,7 +18,18 @@ type ErrResponse struct {
 	HTTPStatusCode int   `json:"-"` // http response status code
 
 	StatusText string `json:"status"`          // user-level status message
+	Code       string `json:"code"`            // machine-readable error code
 	ErrorText  string `json:"error,omitempty"` // application-level error message, for debugging
+
+	Cannon *CannonErrorResponse `json:"cannon,omitempty"` // error answered by an ion cannon
+}
+
+// CannonErrorResponse holds the error answered by an ion cannon.
+type CannonErrorResponse struct {
+	StatusCode int    `json:"status,omitempty"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Retryable  bool   `json:"retryable"`
 }
 
 // Render sets the application-specific error code in AppCode.
@@ -21,12 +38,78 @@ func (e *ErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// errorMappings maps the errors of the domain and the services to the HTTP status code and the error code
+// of the response. The first match wins, so wrapping errors must be listed before the errors they wrap.
+var errorMappings = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrNoValidTarget, http.StatusUnprocessableEntity, "no_valid_target"},
+	{domain.ErrCannonTimeout, http.StatusGatewayTimeout, "cannon_timeout"},
+	{domain.ErrCannonUnreachable, http.StatusBadGateway, "cannon_unreachable"},
+	{domain.ErrCannonRecharging, http.StatusServiceUnavailable, "cannon_recharging"},
+	{domain.ErrCannonBadTarget, http.StatusUnprocessableEntity, "cannon_bad_target"},
+	{domain.ErrCannonStatusFailed, http.StatusBadGateway, "cannon_status_failed"},
+	{domain.ErrCannonFireFailed, http.StatusBadGateway, "cannon_fire_failed"},
+	{domain.ErrNoCannonAvailable, http.StatusServiceUnavailable, "no_cannon_available"},
+	{domain.ErrJobNotFound, http.StatusNotFound, "job_not_found"},
+	{services.ErrJobQueueFull, http.StatusServiceUnavailable, "job_queue_full"},
+	{services.ErrJobInterrupted, http.StatusInternalServerError, "job_interrupted"},
+	{services.ErrPlanNotFound, http.StatusNotFound, "plan_not_found"},
+	{services.ErrPlanExpired, http.StatusGone, "plan_expired"},
+	{services.ErrPlanAlreadyExecuted, http.StatusConflict, "plan_already_executed"},
+	{services.ErrCannonNotFound, http.StatusNotFound, "cannon_not_found"},
+	{services.ErrCannonRetired, http.StatusConflict, "cannon_retired"},
+	{services.ErrCannonExists, http.StatusConflict, "cannon_exists"},
+	{services.ErrInvalidCannon, http.StatusBadRequest, "invalid_cannon"},
+	{ErrIdempotencyKeyReused, http.StatusConflict, "idempotency_key_reused"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+}
+
 // ErrInvalidRequest returns status and error message.
 func ErrInvalidRequest(err error, httpStatusCode int) render.Renderer {
+	code := "invalid_request"
+	if httpStatusCode != http.StatusBadRequest {
+		code = strings.ReplaceAll(strings.ToLower(http.StatusText(httpStatusCode)), " ", "_")
+	}
 	return &ErrResponse{
 		Err:            err,
 		HTTPStatusCode: httpStatusCode,
 		StatusText:     http.StatusText(httpStatusCode),
+		Code:           code,
 		ErrorText:      err.Error(),
 	}
 }
+
+// ErrResponseFor returns the status and error code matching the error of the service.
+// Unknown errors are internal errors. The details of the errors answered by the ion cannons are included.
+func ErrResponseFor(err error) render.Renderer {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			res := &ErrResponse{
+				Err:            err,
+				HTTPStatusCode: m.status,
+				StatusText:     http.StatusText(m.status),
+				Code:           m.code,
+				ErrorText:      err.Error(),
+			}
+			var cannonErr *domain.CannonError
+			if errors.As(err, &cannonErr) {
+				res.Cannon = &CannonErrorResponse{
+					StatusCode: cannonErr.StatusCode,
+					Code:       cannonErr.Code,
+					Message:    cannonErr.Message,
+					Retryable:  cannonErr.Retryable,
+				}
+			}
+			return res
+		}
+	}
+	return ErrInvalidRequest(err, http.StatusInternalServerError)
+}
+
+// ErrorCode returns the error code of the response matching the error of the service.
+func ErrorCode(err error) string {
+	return ErrResponseFor(err).(*ErrResponse).Code
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrResponseFor(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{domain.ErrNoValidTarget, http.StatusUnprocessableEntity, "no_valid_target"},
+		{fmt.Errorf("%w after waiting 1s", domain.ErrNoCannonAvailable), http.StatusServiceUnavailable, "no_cannon_available"},
+		{fmt.Errorf("%w: 500 Internal Server Error", domain.ErrCannonFireFailed), http.StatusBadGateway, "cannon_fire_failed"},
+		{fmt.Errorf("%w: connection refused", domain.ErrCannonUnreachable), http.StatusBadGateway, "cannon_unreachable"},
+		{fmt.Errorf("failed to fire: %w", fmt.Errorf("%w: i/o timeout", domain.ErrCannonTimeout)), http.StatusGatewayTimeout, "cannon_timeout"},
+		{&domain.CannonError{Op: domain.ErrCannonFireFailed, Code: domain.CannonErrorRecharging}, http.StatusServiceUnavailable, "cannon_recharging"},
+		{&domain.CannonError{Op: domain.ErrCannonFireFailed, Code: domain.CannonErrorBadTarget}, http.StatusUnprocessableEntity, "cannon_bad_target"},
+		{&domain.CannonError{Op: domain.ErrCannonFireFailed, Code: domain.CannonErrorInternal}, http.StatusBadGateway, "cannon_fire_failed"},
+		{services.ErrPlanExpired, http.StatusGone, "plan_expired"},
+		{services.ErrJobInterrupted, http.StatusInternalServerError, "job_interrupted"},
+		{errors.New("boom"), http.StatusInternalServerError, "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			res := ErrResponseFor(tt.err).(*ErrResponse)
+			assert.Equal(t, tt.status, res.HTTPStatusCode)
+			assert.Equal(t, tt.code, res.Code)
+			assert.Equal(t, tt.err.Error(), res.ErrorText)
+			assert.Equal(t, tt.code, ErrorCode(tt.err))
+		})
+	}
+
+	// The details of the errors of the ion cannons are kept
+	cannonErr := &domain.CannonError{Op: domain.ErrCannonFireFailed, StatusCode: 503, Code: "recharging", Message: "recharging", Retryable: true}
+	res := ErrResponseFor(fmt.Errorf("failed to fire: %w", cannonErr)).(*ErrResponse)
+	assert.Equal(t, &CannonErrorResponse{StatusCode: 503, Code: "recharging", Message: "recharging", Retryable: true}, res.Cannon)
+
+	res = ErrInvalidRequest(errors.New("invalid body"), http.StatusBadRequest).(*ErrResponse)
+	assert.Equal(t, "invalid_request", res.Code)
+}
@@ -1,21 +1,36 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
 )
 
 type HandlerHTTP struct {
-	svc *services.EndorService
-	v   *validator.Validate
-	r   *chi.Mux
+	svc         *services.EndorService
+	v           *validator.Validate
+	r           *chi.Mux
+	admin       *chi.Mux // routes of the administration of the fleet, served on their own listener
+	adminToken  string
+	idempotency *IdempotencyStore
+	jobs        *services.JobRunner
+	spec        *openapi3.T
+	sunset      time.Time
+
+	batchConcurrency int
+	batchTimeout     time.Duration
 }
 
 // configureRoutes configures the routes for the HTTP handler.
@@ -28,8 +43,8 @@ func (h *HandlerHTTP) configureRoutes() {
 		AllowedOrigins: []string{"https://*", "http://*"},
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", IdempotencyKeyHeader, "Prefer"},
+		ExposedHeaders:   []string{"Link", "Location", DeprecationHeader, SunsetHeader},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -37,11 +52,64 @@ func (h *HandlerHTTP) configureRoutes() {
 	h.r.Use(middleware.RequestID)
 	h.r.Use(middleware.Logger)
 
+	// API documentation
+	h.r.Get("/openapi.json", h.getOpenAPI)
+	h.r.Get("/docs", h.getDocs)
+
+	// Health checks, unversioned as they are used by the infrastructure
+	h.r.Group(func(r chi.Router) {
+		r.Use(render.SetContentType(render.ContentTypeJSON))
+		r.Use(middleware.NoCache)
+		r.Get("/healthz", h.getHealth)
+		r.Get("/readyz", h.getReadiness)
+	})
+
+	// Versioned API, a new version gets its own routes and models over the same service
+	h.r.Route(APIVersionV1, h.routesV1)
+
+	// Unversioned routes of the first version, kept as deprecated aliases of "/v1"
+	h.r.Group(func(r chi.Router) {
+		r.Use(Deprecated(UnversionedDeprecatedAt, h.sunset, APIVersionV1))
+		h.routesV1(r)
+	})
+}
+
+// routesV1 configures the routes of the first version of the API.
+func (h *HandlerHTTP) routesV1(r chi.Router) {
 	// Attack HTTP handlers
-	h.r.Route("/attack", func(r chi.Router) {
+	r.Route("/attack", func(r chi.Router) {
 		r.Group(func(r chi.Router) { // Group use to apply middleweres only to this path
 			r.Use(render.SetContentType(render.ContentTypeJSON))
-			r.Post("/", h.getTarget)
+			r.Use(h.validateRequest)
+			r.With(Idempotent(h.idempotency)).Post("/", h.getTarget)
+			r.Post("/batch", h.attackBatch)
+			r.Post("/plan", h.planTarget)
+			r.Post("/plan/{planID}/confirm", h.confirmPlan)
+			r.Get("/{jobID}", h.getJob)
+		})
+	})
+}
+
+// configureAdminRoutes configures the routes of the administration of the fleet. They are served on
+// their own listener, never on the public port, without CORS as browsers have no business calling them.
+// The requests must have the admin token when one is set.
+func (h *HandlerHTTP) configureAdminRoutes() {
+	h.admin.Use(middleware.RequestID)
+	h.admin.Use(middleware.Logger)
+	if h.adminToken != "" {
+		h.admin.Use(AdminAuth(h.adminToken))
+	}
+
+	// Fleet administration HTTP handlers
+	h.admin.Route(APIVersionV1+"/admin/cannons", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(render.SetContentType(render.ContentTypeJSON))
+			r.Use(h.validateRequest)
+			r.Get("/", h.listCannons)
+			r.Post("/", h.addCannon)
+			r.Delete("/{cannonID}", h.removeCannon)
+			r.Post("/{cannonID}/enable", h.enableCannon)
+			r.Post("/{cannonID}/disable", h.disableCannon)
 		})
 	})
 }
@@ -49,46 +117,150 @@ func (h *HandlerHTTP) configureRoutes() {
 // getTarget is the HTTP handler for the "/attack" endpoint.
 func (h *HandlerHTTP) getTarget(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	data := &AttackRequest{}
-	if err := render.DecodeJSON(r.Body, data); err != nil {
+	attackData, err := h.decodeAttackRequest(r)
+	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
 		return
 	}
 
-	// Validate data
-	// We can implement the validation as a middleware and add to the router.
-	// This would require more work as we need to make a copy of the request body
-	// and the validation middlewere shuold be reuseble by different routes.
-	err := h.validateHTTPAttackPOST(data)
-	if err != nil {
+	if isAsync(r) {
+		h.submitJob(w, r, attackData)
+		return
+	}
+	if err := checkSyncWait(attackData); err != nil {
 		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
 		return
 	}
 
-	// Convert data to Domain Model
-	attackData, err := data.ConvertToAttackDataModel()
+	// Return
+	target, err := h.svc.Attack(r.Context(), attackData)
 	if err != nil {
-		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+		if errors.Is(err, domain.ErrFireSent) {
+			keepIdempotentResponse(r.Context())
+		}
+		render.Render(w, r, ErrResponseFor(err))
 		return
 	}
 
-	// Return
-	target, err := h.svc.Attack(attackData)
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, NewAttackReportResponse(target))
+}
+
+// submitJob queues the attack to be executed asynchronously and returns the job to poll.
+func (h *HandlerHTTP) submitJob(w http.ResponseWriter, r *http.Request, attackData *domain.Radar) {
+	if h.jobs == nil {
+		render.Render(w, r, ErrInvalidRequest(errors.New("asynchronous attacks are not enabled"), http.StatusNotImplemented))
+		return
+	}
+
+	job, err := h.jobs.Submit(attackData)
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+job.ID)
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, NewAttackJobResponse(job))
+}
+
+// getJob is the HTTP handler for the "/attack/{jobID}" endpoint.
+// It returns the status of an asynchronous attack and its report once finished.
+func (h *HandlerHTTP) getJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if h.jobs == nil {
+		render.Render(w, r, ErrInvalidRequest(errors.New("asynchronous attacks are not enabled"), http.StatusNotImplemented))
+		return
+	}
+
+	job, err := h.jobs.Get(chi.URLParam(r, "jobID"))
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, NewAttackJobResponse(job))
+}
+
+// checkSyncWait rejects the synchronous attacks waiting longer than MaxSyncWait for an ion cannon,
+// as the response would be cut by the WriteTimeout while the attack could still fire.
+func checkSyncWait(attack *domain.Radar) error {
+	if attack.MaxWait > MaxSyncWait {
+		return fmt.Errorf("waitForCannonMs above %d requires an asynchronous attack", MaxSyncWait.Milliseconds())
+	}
+	return nil
+}
+
+// isAsync returns true if the client asked for the attack to be executed asynchronously,
+// either with the "Prefer: respond-async" header or the "async=true" query parameter.
+func isAsync(r *http.Request) bool {
+	if r.URL.Query().Get("async") == "true" {
+		return true
+	}
+	for _, prefer := range r.Header.Values("Prefer") {
+		for _, p := range strings.Split(prefer, ",") {
+			if strings.TrimSpace(p) == "respond-async" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planTarget is the HTTP handler for the "/attack/plan" endpoint.
+// It runs the same pipeline as "/attack" but never fires an ion cannon.
+func (h *HandlerHTTP) planTarget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	attackData, err := h.decodeAttackRequest(r)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
 		return
 	}
 
-	var res = &AttackReportResponse{
-		Casualties: target.Casualties,
-		Generation: target.Generation,
-		Target: &Coordinate{
-			X: &target.Target.X,
-			Y: &target.Target.Y,
-		},
+	plan, err := h.svc.Plan(r.Context(), attackData)
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
 	}
+
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, res)
+	render.JSON(w, r, NewAttackPlanResponse(plan))
+}
+
+// confirmPlan is the HTTP handler for the "/attack/plan/{planID}/confirm" endpoint.
+// It fires a plan previously returned by "/attack/plan".
+func (h *HandlerHTTP) confirmPlan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	planID := chi.URLParam(r, "planID")
+
+	target, err := h.svc.Confirm(r.Context(), planID)
+	if err != nil {
+		render.Render(w, r, ErrResponseFor(err))
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, NewAttackReportResponse(target))
+}
+
+// decodeAttackRequest decodes and validates the attack request body and converts it to the domain model.
+func (h *HandlerHTTP) decodeAttackRequest(r *http.Request) (*domain.Radar, error) {
+	data := &AttackRequest{}
+	if err := render.DecodeJSON(r.Body, data); err != nil {
+		return nil, err
+	}
+
+	// Validate data
+	// The request was already validated against the OpenAPI spec, the struct tags are kept as
+	// the handlers can be used without the validation middleware.
+	err := h.validateHTTPAttackPOST(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert data to Domain Model
+	return data.ConvertToAttackDataModel()
 }
 
 // validateHTTPAttackPOST validates the HTTP POST request data for the "/attack" endpoint.
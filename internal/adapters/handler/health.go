@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// getHealth is the HTTP handler for the "/healthz" endpoint.
+// It only reports the process is alive, whatever the state of the ion cannons.
+func (h *HandlerHTTP) getHealth(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &HealthResponse{Status: "ok"})
+}
+
+// getReadiness is the HTTP handler for the "/readyz" endpoint.
+// It answers 503 until enough ion cannons are reachable and once the shutdown has started.
+func (h *HandlerHTTP) getReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.svc.StatusTimeout())
+	defer cancel()
+	readiness := h.svc.CheckReadiness(ctx)
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	render.Status(r, status)
+	render.JSON(w, r, NewReadinessResponse(readiness))
+}
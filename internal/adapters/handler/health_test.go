@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+func TestHealth(t *testing.T) {
+	reachable := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Generation: 1, Available: false}, nil
+		},
+	}
+	unreachable := &mocks.IonCannonClientMock{
+		CannonID: "cannon-2",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return nil, domain.ErrCannonUnreachable
+		},
+	}
+
+	get := func(s *ServerHTTP, path string) (*httptest.ResponseRecorder, *ReadinessResponse) {
+		rec := httptest.NewRecorder()
+		s.h.r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assertValidResponse(t, s.h.spec, http.MethodGet, path, rec)
+
+		res := &ReadinessResponse{}
+		json.Unmarshal(rec.Body.Bytes(), res)
+		return rec, res
+	}
+
+	t.Run("alive whatever the state of the cannons", func(t *testing.T) {
+		s := NewHTTPServer(services.NewEndorService(nil), validator.New())
+		rec, _ := get(s, "/healthz")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+	})
+
+	t.Run("ready with enough reachable cannons", func(t *testing.T) {
+		svc := services.NewEndorService([]adapters.IonCannon{reachable, unreachable})
+		rec, res := get(NewHTTPServer(svc, validator.New()), "/readyz")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, res.Ready)
+		assert.Equal(t, 1, res.Reachable)
+		assert.Equal(t, []*CannonHealthResponse{
+			{ID: "cannon-1", Reachable: true, Generation: 1},
+			{ID: "cannon-2", Error: domain.ErrCannonUnreachable.Error()},
+		}, res.Cannons)
+		assert.Equal(t, services.DefaultMaxConcurrency, res.Pool.Capacity)
+	})
+
+	t.Run("not ready without enough reachable cannons", func(t *testing.T) {
+		svc := services.NewEndorService([]adapters.IonCannon{reachable, unreachable}, services.WithMinReadyCannons(2))
+		rec, res := get(NewHTTPServer(svc, validator.New()), "/readyz")
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.False(t, res.Ready)
+		assert.Equal(t, 2, res.MinCannons)
+	})
+
+	t.Run("not ready once shutting down", func(t *testing.T) {
+		svc := services.NewEndorService([]adapters.IonCannon{reachable})
+		s := NewHTTPServer(svc, validator.New())
+		svc.Shutdown()
+		rec, res := get(s, "/readyz")
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.True(t, res.ShuttingDown)
+	})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// DefaultIdempotencyTTL is the time a stored response is replayed for the same idempotency key.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyKeyHeader is the header clients use to make retries of a request safe.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is reused with a different request body,
+// or with a synchronous request after an asynchronous one and vice versa.
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used with a different request")
+
+// idempotentResponse is the response stored for an idempotency key.
+type idempotentResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	keep   bool // stored even if failed, as the ion cannon may have fired
+}
+
+type idempotencyStateKey struct{}
+
+// idempotencyState is shared by the Idempotent middleware with the handler through the request context.
+type idempotencyState struct {
+	keep bool
+}
+
+// keepIdempotentResponse stores the response of the request even if it fails, so a retry with the same
+// idempotency key gets it instead of firing again. It must be called when the ion cannon may have fired.
+func keepIdempotentResponse(ctx context.Context) {
+	if state, ok := ctx.Value(idempotencyStateKey{}).(*idempotencyState); ok {
+		state.keep = true
+	}
+}
+
+type idempotencyEntry struct {
+	bodyHash  [sha256.Size]byte
+	expiresAt time.Time
+	done      chan struct{} // closed once the first execution finished
+	response  *idempotentResponse
+}
+
+// IdempotencyStore keeps the responses of requests sent with an idempotency key.
+// Only successful responses, and the failures kept by the handlers, are stored. Other failed executions
+// are forgotten so they can be retried.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+}
+
+// NewIdempotencyStore creates a new store that keeps the responses for the given time.
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: map[string]*idempotencyEntry{},
+	}
+}
+
+// idempotencyBody returns the body compared between the requests sent with the same idempotency key.
+// JSON bodies are compared regardless of their formatting and the order of their properties.
+func idempotencyBody(body []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil || dec.More() {
+		return body
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// Do executes fn only once for the given key and body and returns its response.
+// Concurrent calls with the same key wait for the first execution to finish and get the same response.
+// replayed is true when the response was not produced by this call.
+func (s *IdempotencyStore) Do(
+	ctx context.Context,
+	key string,
+	body []byte,
+	fn func() *idempotentResponse,
+) (res *idempotentResponse, replayed bool, err error) {
+	hash := sha256.Sum256(body)
+
+	for {
+		s.mu.Lock()
+		entry, ok := s.entries[key]
+		if ok && entry.response != nil && time.Now().After(entry.expiresAt) {
+			delete(s.entries, key)
+			ok = false
+		}
+
+		if !ok {
+			entry = &idempotencyEntry{bodyHash: hash, done: make(chan struct{})}
+			s.removeExpired()
+			s.entries[key] = entry
+			s.mu.Unlock()
+			return s.execute(key, entry, fn), false, nil
+		}
+		s.mu.Unlock()
+
+		if entry.bodyHash != hash {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+
+		// Wait for the first execution to finish
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+
+		if entry.response != nil {
+			return entry.response, true, nil
+		}
+		// The first execution failed and was forgotten, try again.
+	}
+}
+
+// execute runs fn and stores its response if it was successful or kept. The entry is forgotten if fn panics,
+// so the requests waiting for it are retried.
+func (s *IdempotencyStore) execute(key string, entry *idempotencyEntry, fn func() *idempotentResponse) (res *idempotentResponse) {
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if res != nil && (res.keep || res.status >= 200 && res.status < 300) {
+			entry.response = res
+			entry.expiresAt = time.Now().Add(s.ttl)
+		} else {
+			delete(s.entries, key)
+		}
+		close(entry.done)
+	}()
+
+	return fn()
+}
+
+// removeExpired removes the expired responses. It must be called with the lock held.
+func (s *IdempotencyStore) removeExpired() {
+	now := time.Now()
+	for key, entry := range s.entries {
+		if entry.response != nil && now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// idempotencyKey returns the key of the store for the idempotency key of a request. It does not depend on the path,
+// so the versioned and unversioned routes of an endpoint share the keys.
+func idempotencyKey(method string, key string) string {
+	return method + " " + key
+}
+
+// Idempotent is a middleware that makes requests with an Idempotency-Key header safe to retry.
+// Retries with the same key, body and mode (synchronous or asynchronous) get the stored response,
+// a different body or mode returns a conflict.
+func Idempotent(store *IdempotencyStore) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := idempotencyBody(body)
+			if isAsync(r) {
+				fingerprint = append([]byte("async "), fingerprint...)
+			}
+			res, replayed, err := store.Do(r.Context(), idempotencyKey(r.Method, key), fingerprint, func() *idempotentResponse {
+				state := &idempotencyState{}
+				rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+				next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), idempotencyStateKey{}, state)))
+				return &idempotentResponse{status: rec.status, header: rec.header, body: rec.body.Bytes(), keep: state.keep}
+			})
+			if errors.Is(err, ErrIdempotencyKeyReused) {
+				render.Render(w, r, ErrResponseFor(err))
+				return
+			}
+			if err != nil {
+				render.Render(w, r, ErrInvalidRequest(err, http.StatusRequestTimeout))
+				return
+			}
+
+			for k, v := range res.header {
+				w.Header()[k] = v
+			}
+			if replayed {
+				w.Header().Set("Idempotent-Replayed", "true")
+			}
+			w.WriteHeader(res.status)
+			w.Write(res.body)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// responseRecorder captures the response of a handler so it can be stored and replayed.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotent(t *testing.T) {
+	var calls int32
+	status := http.StatusOK
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"casualties":1}`))
+	})
+
+	sendTo := func(h http.Handler, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	send := func(h http.Handler, key, body string) *httptest.ResponseRecorder {
+		return sendTo(h, "/attack", key, body)
+	}
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		first := send(h, "key-1", `{"a":1}`)
+		second := send(h, "key-1", `{"a":1}`)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("versioned routes share the keys", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		for _, path := range []string{"/attack", "/v1/attack", "/v1/attack/"} {
+			sendTo(h, path, "key-1", `{"a":1}`)
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("rejects a different body", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		send(h, "key-1", `{"a":1}`)
+		res := send(h, "key-1", `{"a":2}`)
+
+		assert.Equal(t, http.StatusConflict, res.Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("concurrent duplicates wait for the first execution", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res := send(h, "key-1", `{"a":1}`)
+				assert.Equal(t, http.StatusOK, res.Code)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("keys expire", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Nanosecond))(next)
+
+		send(h, "key-1", `{"a":1}`)
+		send(h, "key-1", `{"a":1}`)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("failed requests are not stored", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		status = http.StatusBadRequest
+		defer func() { status = http.StatusOK }()
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		send(h, "key-1", `{"a":1}`)
+		send(h, "key-1", `{"a":1}`)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("failures kept by the handler are replayed", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			keepIdempotentResponse(r.Context())
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+
+		send(h, "key-1", `{"a":1}`)
+		res := send(h, "key-1", `{"a":1}`)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, http.StatusBadGateway, res.Code)
+		assert.Equal(t, "true", res.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("a panic forgets the key", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		assert.Panics(t, func() { send(h, "key-1", `{"a":1}`) })
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- send(h, "key-1", `{"a":1}`) }()
+		select {
+		case res := <-done:
+			assert.Equal(t, http.StatusOK, res.Code)
+		case <-time.After(time.Second):
+			t.Fatal("Expected the key to be released after the panic")
+		}
+	})
+
+	t.Run("synchronous and asynchronous requests do not share the responses", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		sendTo(h, "/attack?async=true", "key-1", `{"a":1}`)
+		res := send(h, "key-1", `{"a":1}`)
+
+		assert.Equal(t, http.StatusConflict, res.Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("requests without key are not affected", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		h := Idempotent(NewIdempotencyStore(time.Minute))(next)
+
+		send(h, "", `{"a":1}`)
+		send(h, "", `{"a":1}`)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
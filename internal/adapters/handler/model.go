@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
 )
 
-// This can be changed with an OpenAPI spec for example
+// The models mirror the schemas of the OpenAPI spec in openapi.yaml, any change must be applied to both.
 
 type Coordinate struct {
 	X *int `json:"x" validate:"required,min=0"`
@@ -25,14 +28,17 @@ type Scan struct {
 }
 
 type AttackRequest struct {
-	Protocols []string `json:"protocols" validate:"required,dive,required"`
-	Scan      []*Scan  `json:"scan" validate:"required,dive,required"`
+	Protocols       []string `json:"protocols" validate:"required,dive,required"`
+	Scan            []*Scan  `json:"scan" validate:"required,dive,required"`
+	WaitForCannonMs int      `json:"waitForCannonMs" validate:"min=0,max=60000"` // opt-in wait for an available ion cannon
 }
 
 // TODO: Review how to improve the convertion of the data
 func (rq AttackRequest) ConvertToAttackDataModel() (*domain.Radar, error) {
 	// Transforming data to the domain model.
-	var attack = &domain.Radar{}
+	var attack = &domain.Radar{
+		MaxWait: time.Duration(rq.WaitForCannonMs) * time.Millisecond,
+	}
 
 	for _, protocol := range rq.Protocols {
 		proto, err := domain.ParseStringToProtocolType(protocol)
@@ -67,4 +73,282 @@ type AttackReportResponse struct {
 	Casualties int         `json:"casualties"`
 	Generation int         `json:"generation"`
 	Target     *Coordinate `json:"target" validate:"required"`
+	WaitTimeMs int64       `json:"waitTimeMs,omitempty"`
+
+	CannonID   string           `json:"cannonId"`
+	EnemyType  string           `json:"enemyType"`
+	Distance   float64          `json:"distance"`
+	Candidates int              `json:"candidates"`
+	Pipeline   []string         `json:"pipeline"`
+	Timings    *TimingsResponse `json:"timings"`
+
+	ExpectedCasualties float64 `json:"expectedCasualties"`
+	CasualtyDeviation  float64 `json:"casualtyDeviation"`
+	Anomalous          bool    `json:"anomalous"`
+}
+
+// TimingsResponse holds the time in milliseconds spent in every phase of an attack.
+type TimingsResponse struct {
+	TargetingMs   float64 `json:"targetingMs"`
+	StatusCheckMs float64 `json:"statusCheckMs"`
+	FireMs        float64 `json:"fireMs"`
+	TotalMs       float64 `json:"totalMs"`
+}
+
+// NewAttackReportResponse transforms the domain attack report to the response model.
+func NewAttackReportResponse(report *domain.Report) *AttackReportResponse {
+	return &AttackReportResponse{
+		Casualties: report.Casualties,
+		Generation: report.Generation,
+		Target: &Coordinate{
+			X: &report.Target.X,
+			Y: &report.Target.Y,
+		},
+		WaitTimeMs: report.WaitTime.Milliseconds(),
+
+		CannonID:   report.CannonID,
+		EnemyType:  string(report.EnemyType),
+		Distance:   report.Target.GetDistance(),
+		Candidates: report.Candidates,
+		Pipeline:   report.Pipeline,
+		Timings: &TimingsResponse{
+			TargetingMs:   toMilliseconds(report.Timings.Targeting),
+			StatusCheckMs: toMilliseconds(report.Timings.StatusCheck),
+			FireMs:        toMilliseconds(report.Timings.Fire),
+			TotalMs:       toMilliseconds(report.Timings.Total),
+		},
+
+		ExpectedCasualties: report.ExpectedCasualties,
+		CasualtyDeviation:  report.CasualtyDeviation,
+		Anomalous:          report.Anomalous,
+	}
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+type CannonCandidateResponse struct {
+	ID         string `json:"id"`
+	Generation int    `json:"generation"`
+	Available  bool   `json:"available"`
+	Error      string `json:"error,omitempty"`
+}
+
+type AttackPlanResponse struct {
+	ID         string                     `json:"id"`
+	ExpiresAt  time.Time                  `json:"expiresAt"`
+	Target     *Coordinate                `json:"target"`
+	Enemies    *Enemy                     `json:"enemies"`
+	Distance   float64                    `json:"distance"`
+	Pipeline   []string                   `json:"pipeline"`
+	Candidates []*CannonCandidateResponse `json:"candidates"`
+	Cannon     *CannonCandidateResponse   `json:"cannon"`
+
+	ExpectedCasualties float64 `json:"expectedCasualties"`
+}
+
+// NewAttackPlanResponse transforms the domain attack plan to the response model.
+func NewAttackPlanResponse(plan *domain.AttackPlan) *AttackPlanResponse {
+	res := &AttackPlanResponse{
+		ID:        plan.ID,
+		ExpiresAt: plan.ExpiresAt,
+		Target: &Coordinate{
+			X: &plan.Target.Coordinates.X,
+			Y: &plan.Target.Coordinates.Y,
+		},
+		Enemies: &Enemy{
+			Type:   string(plan.Target.Enemies.Type),
+			Number: &plan.Target.Enemies.Number,
+		},
+		Distance:   plan.Target.Coordinates.GetDistance(),
+		Pipeline:   plan.Pipeline,
+		Candidates: []*CannonCandidateResponse{},
+
+		ExpectedCasualties: plan.ExpectedCasualties,
+	}
+
+	for _, candidate := range plan.Candidates {
+		c := newCannonCandidateResponse(candidate)
+		res.Candidates = append(res.Candidates, c)
+		if candidate == plan.Cannon {
+			res.Cannon = c
+		}
+	}
+	return res
+}
+
+func newCannonCandidateResponse(candidate *domain.CannonCandidate) *CannonCandidateResponse {
+	res := &CannonCandidateResponse{
+		ID:         candidate.ID,
+		Generation: candidate.Generation,
+		Available:  candidate.Available,
+	}
+	if candidate.Err != nil {
+		res.Error = candidate.Err.Error()
+	}
+	return res
+}
+
+type AttackJobResponse struct {
+	ID        string                `json:"id"`
+	Status    string                `json:"status"`
+	Report    *AttackReportResponse `json:"report,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	ErrorCode string                `json:"errorCode,omitempty"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// NewAttackJobResponse transforms the domain attack job to the response model.
+func NewAttackJobResponse(job *domain.Job) *AttackJobResponse {
+	res := &AttackJobResponse{
+		ID:        job.ID,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		ErrorCode: job.ErrorCode,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if job.Report != nil {
+		res.Report = NewAttackReportResponse(job.Report)
+	}
+	return res
+}
+
+type BatchAttackItem struct {
+	ID             string         `json:"id"`             // reference of the client, returned with the result
+	IdempotencyKey string         `json:"idempotencyKey"` // makes the retries of the item safe, like the Idempotency-Key header
+	Radar          *AttackRequest `json:"radar" validate:"required"`
+
+	rawRadar json.RawMessage // radar as sent, so an idempotency key matches the same body sent to /attack
+}
+
+// UnmarshalJSON decodes the item keeping the radar as sent.
+func (item *BatchAttackItem) UnmarshalJSON(data []byte) error {
+	type batchAttackItem BatchAttackItem
+	if err := json.Unmarshal(data, (*batchAttackItem)(item)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Radar json.RawMessage `json:"radar"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	item.rawRadar = raw.Radar
+	return nil
+}
+
+type BatchAttackResult struct {
+	Index    int                   `json:"index"`
+	ID       string                `json:"id,omitempty"`
+	Status   int                   `json:"status"`
+	Report   *AttackReportResponse `json:"report,omitempty"`
+	Error    *ErrResponse          `json:"error,omitempty"`
+	Replayed bool                  `json:"replayed,omitempty"`
+}
+
+type BatchAttackResponse struct {
+	Results   []*BatchAttackResult `json:"results"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+}
+
+// CannonRequest adds an ion cannon to the fleet. It accepts no file paths nor secrets: the TLS configuration
+// of the environment is used, and the cannons signing the fire commands are defined in the fleet file.
+type CannonRequest struct {
+	ID  string `json:"id"`
+	URL string `json:"url" validate:"required"`
+}
+
+// ConvertToCannonDefinition transforms the request to the domain model.
+func (rq CannonRequest) ConvertToCannonDefinition() *domain.CannonDefinition {
+	return &domain.CannonDefinition{ID: rq.ID, URL: rq.URL}
+}
+
+type CannonResponse struct {
+	ID       string      `json:"id"`
+	URL      string      `json:"url"`
+	Position *Coordinate `json:"position,omitempty"`
+	Tags     []string    `json:"tags,omitempty"`
+	Source   string      `json:"source,omitempty"`
+	Enabled  bool        `json:"enabled"`
+}
+
+// NewCannonResponse transforms the ion cannon of the fleet to the response model.
+func NewCannonResponse(cannon *services.FleetCannon) *CannonResponse {
+	res := &CannonResponse{
+		ID:      cannon.ID,
+		URL:     cannon.URL,
+		Tags:    cannon.Tags,
+		Source:  cannon.Source,
+		Enabled: cannon.Enabled,
+	}
+	if cannon.Position != nil {
+		res.Position = &Coordinate{
+			X: &cannon.Position.X,
+			Y: &cannon.Position.Y,
+		}
+	}
+	return res
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Ready        bool                    `json:"ready"`
+	ShuttingDown bool                    `json:"shuttingDown"`
+	MinCannons   int                     `json:"minCannons"`
+	Reachable    int                     `json:"reachable"`
+	Cannons      []*CannonHealthResponse `json:"cannons"`
+	Pool         *WorkerPoolResponse     `json:"pool"`
+}
+
+type WorkerPoolResponse struct {
+	Capacity   int     `json:"capacity"`
+	InUse      int     `json:"inUse"`
+	Saturation float64 `json:"saturation"`
+	Saturated  uint64  `json:"saturated"`
+}
+
+type CannonHealthResponse struct {
+	ID         string `json:"id"`
+	Reachable  bool   `json:"reachable"`
+	Generation int    `json:"generation,omitempty"`
+	Available  bool   `json:"available"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewReadinessResponse transforms the readiness of the service to the response model.
+func NewReadinessResponse(readiness *services.Readiness) *ReadinessResponse {
+	res := &ReadinessResponse{
+		Ready:        readiness.Ready,
+		ShuttingDown: readiness.ShuttingDown,
+		MinCannons:   readiness.MinCannons,
+		Reachable:    readiness.Reachable,
+		Cannons:      make([]*CannonHealthResponse, 0, len(readiness.Cannons)),
+		Pool: &WorkerPoolResponse{
+			Capacity:   readiness.Pool.Capacity,
+			InUse:      readiness.Pool.InUse,
+			Saturation: readiness.Pool.Saturation,
+			Saturated:  readiness.Pool.Saturated,
+		},
+	}
+	for _, candidate := range readiness.Cannons {
+		cannon := &CannonHealthResponse{
+			ID:         candidate.ID,
+			Reachable:  candidate.Err == nil,
+			Generation: candidate.Generation,
+			Available:  candidate.Available,
+		}
+		if candidate.Err != nil {
+			cannon.Error = candidate.Err.Error()
+		}
+		res.Cannons = append(res.Cannons, cannon)
+	}
+	return res
 }
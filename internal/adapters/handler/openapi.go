@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// openAPISpec is the OpenAPI document of the HTTP API, the source of truth of its contract.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+func init() {
+	// Keep the validation errors short, the schema is published in "/openapi.json"
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// LoadOpenAPI loads and validates the OpenAPI document of the HTTP API.
+func LoadOpenAPI() (*openapi3.T, error) {
+	spec, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the OpenAPI spec. err: %s", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec. err: %s", err)
+	}
+	return spec, nil
+}
+
+// validateRequest validates the requests against the OpenAPI spec before they reach the handlers.
+// It must be applied to the routes of a chi router, as the matched route pattern locates the operation of the spec.
+// Routes missing from the spec are not validated.
+func (h *HandlerHTTP) validateRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := chi.RouteContext(r.Context())
+		path := specPath(rctx.RoutePattern())
+		pathItem := h.spec.Paths.Find(path)
+		if pathItem == nil || pathItem.GetOperation(r.Method) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		params := make(map[string]string, len(rctx.URLParams.Keys))
+		for i, key := range rctx.URLParams.Keys {
+			params[key] = rctx.URLParams.Values[i]
+		}
+
+		// The handlers decode the body as JSON whatever its content type, so it is validated the same way.
+		// Streamed bodies are validated item by item by the handlers.
+		operation := pathItem.GetOperation(r.Method)
+		req := r
+		streamed := false
+		if operation.RequestBody != nil {
+			streamed = isNDJSON(r) && operation.RequestBody.Value.Content.Get(ContentTypeNDJSON) != nil
+			if !streamed {
+				req = r.Clone(r.Context())
+				req.Header.Set("Content-Type", "application/json")
+			}
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: params,
+			Route: &routers.Route{
+				Spec:      h.spec,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    r.Method,
+				Operation: operation,
+			},
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				ExcludeRequestBody: streamed,
+			},
+		}
+		err := openapi3filter.ValidateRequest(r.Context(), input)
+		r.Body = req.Body // restored by the validation once read
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(err, http.StatusBadRequest))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// specPath returns the path of the OpenAPI spec matching a chi route pattern. The paths of the spec are
+// relative to the version of the API, and chi mounts the root of the sub-routers with a trailing slash.
+func specPath(pattern string) string {
+	pattern = versionPrefix.ReplaceAllString(pattern, "")
+	if pattern == "/" || pattern == "" {
+		return "/"
+	}
+	return strings.TrimSuffix(pattern, "/")
+}
+
+// getOpenAPI is the HTTP handler for the "/openapi.json" endpoint.
+func (h *HandlerHTTP) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, h.spec)
+}
+
+// docsPage renders the OpenAPI spec with Swagger UI.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Endor Service API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => { window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" }); };
+  </script>
+</body>
+</html>
+`
+
+// getDocs is the HTTP handler for the "/docs" endpoint.
+func (h *HandlerHTTP) getDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(docsPage))
+}
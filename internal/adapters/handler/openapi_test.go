@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+// specModels maps the schemas of the OpenAPI spec to the models of the handler.
+// The required properties of the request models are the fields validated as required,
+// the ones of the response models are the fields never omitted.
+var specModels = map[string]struct {
+	model   interface{}
+	request bool
+}{
+	"Coordinate":          {Coordinate{}, true},
+	"Enemy":               {Enemy{}, true},
+	"Scan":                {Scan{}, true},
+	"AttackRequest":       {AttackRequest{}, true},
+	"CannonRequest":       {CannonRequest{}, true},
+	"BatchAttackItem":     {BatchAttackItem{}, true},
+	"AttackReport":        {AttackReportResponse{}, false},
+	"Timings":             {TimingsResponse{}, false},
+	"CannonCandidate":     {CannonCandidateResponse{}, false},
+	"AttackPlan":          {AttackPlanResponse{}, false},
+	"AttackJob":           {AttackJobResponse{}, false},
+	"Cannon":              {CannonResponse{}, false},
+	"CannonError":         {CannonErrorResponse{}, false},
+	"Error":               {ErrResponse{}, false},
+	"BatchAttackResult":   {BatchAttackResult{}, false},
+	"BatchAttackResponse": {BatchAttackResponse{}, false},
+	"Health":              {HealthResponse{}, false},
+	"Readiness":           {ReadinessResponse{}, false},
+	"CannonHealth":        {CannonHealthResponse{}, false},
+	"WorkerPool":          {WorkerPoolResponse{}, false},
+}
+
+func TestOpenAPI_ModelsMatchSpec(t *testing.T) {
+	spec, err := LoadOpenAPI()
+	require.NoError(t, err)
+
+	schemaNames := map[reflect.Type]string{}
+	for name, m := range specModels {
+		schemaNames[reflect.TypeOf(m.model)] = name
+	}
+	for name := range spec.Components.Schemas {
+		assert.Contains(t, specModels, name, "schema %s has no model", name)
+	}
+
+	for name, m := range specModels {
+		schemaRef, ok := spec.Components.Schemas[name]
+		if !assert.True(t, ok, "model of schema %s is missing from the spec", name) {
+			continue
+		}
+		schema := schemaRef.Value
+		typ := reflect.TypeOf(m.model)
+
+		properties := []string{}
+		required := []string{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag := strings.Split(field.Tag.Get("json"), ",")
+			if tag[0] == "-" || tag[0] == "" {
+				continue
+			}
+			properties = append(properties, tag[0])
+
+			if m.request && hasOption(field.Tag.Get("validate"), "required") ||
+				!m.request && !hasOption(strings.Join(tag[1:], ","), "omitempty") {
+				required = append(required, tag[0])
+			}
+
+			property, ok := schema.Properties[tag[0]]
+			if assert.True(t, ok, "property %s.%s is missing from the spec", name, tag[0]) {
+				assertSchemaType(t, name+"."+tag[0], field.Type, property, schemaNames)
+			}
+		}
+
+		specProperties := []string{}
+		for property := range schema.Properties {
+			specProperties = append(specProperties, property)
+		}
+		specRequired := append([]string{}, schema.Required...)
+		sort.Strings(properties)
+		sort.Strings(specProperties)
+		sort.Strings(required)
+		sort.Strings(specRequired)
+		assert.Equal(t, properties, specProperties, "properties of %s", name)
+		assert.Equal(t, required, specRequired, "required properties of %s", name)
+	}
+}
+
+// assertSchemaType asserts the schema of a property matches the Go type of the field.
+func assertSchemaType(t *testing.T, name string, typ reflect.Type, schemaRef *openapi3.SchemaRef, schemaNames map[reflect.Type]string) {
+	t.Helper()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if ref, ok := schemaNames[typ]; ok {
+		assert.Equal(t, "#/components/schemas/"+ref, schemaRef.Ref, "reference of %s", name)
+		return
+	}
+
+	expected := ""
+	switch typ.Kind() {
+	case reflect.String:
+		expected = openapi3.TypeString
+	case reflect.Bool:
+		expected = openapi3.TypeBoolean
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint64:
+		expected = openapi3.TypeInteger
+	case reflect.Float32, reflect.Float64:
+		expected = openapi3.TypeNumber
+	case reflect.Slice:
+		expected = openapi3.TypeArray
+		if assert.NotNil(t, schemaRef.Value.Items, "items of %s", name) {
+			assertSchemaType(t, name+"[]", typ.Elem(), schemaRef.Value.Items, schemaNames)
+		}
+	case reflect.Struct:
+		if typ == reflect.TypeOf(time.Time{}) {
+			expected = openapi3.TypeString
+			assert.Equal(t, "date-time", schemaRef.Value.Format, "format of %s", name)
+			break
+		}
+		t.Errorf("%s has no schema in the spec", typ)
+		return
+	default:
+		t.Errorf("unexpected type %s of %s", typ, name)
+		return
+	}
+	assert.Equal(t, expected, schemaRef.Value.Type, "type of %s", name)
+}
+
+func hasOption(tag string, option string) bool {
+	for _, o := range strings.Split(tag, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	s := NewHTTPServer(services.NewEndorService(nil), validator.New())
+
+	walk := func(router chi.Routes) []string {
+		routes := []string{}
+		err := chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			routes = append(routes, method+" "+strings.TrimSuffix(route, "/"))
+			return nil
+		})
+		require.NoError(t, err)
+		sort.Strings(routes)
+		return routes
+	}
+
+	// Every operation is served under the URL of its server, and the ones of the first version
+	// also under their deprecated unversioned alias. Servers with a host are served by the admin listener.
+	operations := []string{}
+	adminOperations := []string{}
+	for path, item := range s.h.spec.Paths {
+		servers := s.h.spec.Servers
+		if len(item.Servers) > 0 {
+			servers = item.Servers
+		}
+		for method := range item.Operations() {
+			for _, server := range servers {
+				u, err := url.Parse(server.URL)
+				require.NoError(t, err)
+				prefix := strings.TrimSuffix(u.Path, "/")
+				if u.Host != "" {
+					adminOperations = append(adminOperations, method+" "+prefix+path)
+					continue
+				}
+				operations = append(operations, method+" "+prefix+path)
+				if prefix == APIVersionV1 {
+					operations = append(operations, method+" "+path)
+				}
+			}
+		}
+	}
+
+	sort.Strings(operations)
+	sort.Strings(adminOperations)
+	assert.Equal(t, operations, walk(s.h.r))
+	assert.Equal(t, adminOperations, walk(s.h.admin))
+}
+
+func TestOpenAPI_ValidateRequest(t *testing.T) {
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Generation: 1, Available: true}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (int, int, error) {
+			return enemies, 1, nil
+		},
+	}
+	s := NewHTTPServer(services.NewEndorService([]adapters.IonCannon{cannon}), validator.New())
+
+	send := func(method string, path string, contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		if strings.HasPrefix(path, APIVersionV1+"/admin/") {
+			s.h.admin.ServeHTTP(rec, req)
+		} else {
+			s.h.r.ServeHTTP(rec, req)
+		}
+		return rec
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		header string
+		body   string
+		status int
+	}{
+		{
+			name:   "valid attack without content type",
+			method: http.MethodPost,
+			path:   "/attack",
+			route:  "/attack",
+			body:   `{"protocols":["closest-enemies"],"scan":[{"coordinates":{"x":0,"y":40},"enemies":{"type":"soldier","number":10}}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "valid plan sent as a form",
+			method: http.MethodPost,
+			path:   "/v1/attack/plan",
+			route:  "/attack/plan",
+			header: "application/x-www-form-urlencoded",
+			body:   `{"protocols":["closest-enemies"],"scan":[{"coordinates":{"x":0,"y":40},"enemies":{"type":"soldier","number":10}}]}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "unknown protocol",
+			method: http.MethodPost,
+			path:   "/v1/attack",
+			route:  "/attack",
+			body:   `{"protocols":["closest-allies"],"scan":[{"coordinates":{"x":0,"y":40},"enemies":{"type":"soldier","number":10}}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "negative coordinate",
+			method: http.MethodPost,
+			path:   "/attack/plan",
+			route:  "/attack/plan",
+			body:   `{"protocols":["closest-enemies"],"scan":[{"coordinates":{"x":-1,"y":40},"enemies":{"type":"soldier","number":10}}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "synchronous attack waiting longer than the response",
+			method: http.MethodPost,
+			path:   "/v1/attack",
+			route:  "/attack",
+			body:   `{"protocols":["closest-enemies"],"waitForCannonMs":6000,"scan":[{"coordinates":{"x":0,"y":40},"enemies":{"type":"soldier","number":10}}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing body",
+			method: http.MethodPost,
+			path:   "/attack/",
+			route:  "/attack",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "cannon without URL",
+			method: http.MethodPost,
+			path:   "/v1/admin/cannons",
+			route:  "/admin/cannons",
+			body:   `{"id":"cannon-2"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "enable cannon without body",
+			method: http.MethodPost,
+			path:   "/v1/admin/cannons/cannon-1/enable",
+			route:  "/admin/cannons/{cannonID}/enable",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := send(tt.method, tt.path, tt.header, tt.body)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+			assertValidResponse(t, s.h.spec, tt.method, tt.route, rec)
+		})
+	}
+
+	t.Run("serves the spec and the docs", func(t *testing.T) {
+		rec := send(http.MethodGet, "/openapi.json", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		loaded, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, s.h.spec.Info.Title, loaded.Info.Title)
+
+		rec = send(http.MethodGet, "/docs", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "/openapi.json")
+	})
+}
+
+// assertValidResponse asserts the response matches the operation of the spec.
+func assertValidResponse(t *testing.T, spec *openapi3.T, method string, path string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	item := spec.Paths.Find(path)
+	if !assert.NotNil(t, item, "no operation for %s %s", method, path) {
+		return
+	}
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: httptest.NewRequest(method, path, nil),
+			Route:   &routers.Route{Spec: spec, Path: path, PathItem: item, Method: method, Operation: item.GetOperation(method)},
+		},
+		Status: rec.Code,
+		Header: rec.Header(),
+	}
+	input.SetBodyBytes(rec.Body.Bytes())
+	assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), input))
+}
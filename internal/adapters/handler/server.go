@@ -11,33 +11,120 @@ import (
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
 )
 
+const (
+	// WriteTimeout is the maximum time to write the response of a request.
+	WriteTimeout = 10 * time.Second
+
+	// DefaultAdminAddr is the default address of the listener of the administration of the fleet,
+	// only reachable from the host of the service.
+	DefaultAdminAddr = "127.0.0.1:3100"
+
+	// MaxSyncWait is the maximum time a synchronous attack waits for an available ion cannon.
+	// The status checks before the wait and the fire command after it must fit in the WriteTimeout,
+	// longer waits need an asynchronous attack.
+	MaxSyncWait = 5 * time.Second
+)
+
 type ServerHTTP struct {
-	svc *services.EndorService
-	srv *http.Server
-	h   *HandlerHTTP
+	svc   *services.EndorService
+	srv   *http.Server
+	admin *http.Server
+	h     *HandlerHTTP
+}
+
+// ServerOption configures the HTTP server.
+type ServerOption func(*HandlerHTTP)
+
+// WithIdempotencyTTL sets the time a response is replayed for the same idempotency key.
+func WithIdempotencyTTL(ttl time.Duration) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.idempotency = NewIdempotencyStore(ttl)
+	}
+}
+
+// WithJobRunner enables asynchronous attacks executed by the given runner.
+func WithJobRunner(jobs *services.JobRunner) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.jobs = jobs
+	}
+}
+
+// WithUnversionedSunset sets the date announced for the removal of the deprecated unversioned routes.
+func WithUnversionedSunset(sunset time.Time) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.sunset = sunset
+	}
+}
+
+// WithBatchConcurrency sets the number of items of a batch attacking concurrently.
+func WithBatchConcurrency(n int) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.batchConcurrency = n
+	}
 }
 
-func NewHTTPServer(endorService *services.EndorService, validate *validator.Validate) *ServerHTTP {
+// WithBatchTimeout sets the maximum time a batch attacks its items.
+func WithBatchTimeout(timeout time.Duration) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.batchTimeout = timeout
+	}
+}
+
+// WithAdminToken makes the administration of the fleet require the token as a bearer token.
+func WithAdminToken(token string) ServerOption {
+	return func(h *HandlerHTTP) {
+		h.adminToken = token
+	}
+}
+
+// NewHTTPServer creates the HTTP server of the service.
+// It panics if the embedded OpenAPI spec is invalid, which is covered by the tests of the package.
+func NewHTTPServer(endorService *services.EndorService, validate *validator.Validate, opts ...ServerOption) *ServerHTTP {
+	spec, err := LoadOpenAPI()
+	if err != nil {
+		panic(err)
+	}
+
 	handler := &HandlerHTTP{
-		svc: endorService,
-		v:   validate,
-		r:   chi.NewRouter(),
+		svc:         endorService,
+		v:           validate,
+		r:           chi.NewRouter(),
+		admin:       chi.NewRouter(),
+		idempotency: NewIdempotencyStore(DefaultIdempotencyTTL),
+		spec:        spec,
+		sunset:      DefaultUnversionedSunset,
+
+		batchConcurrency: DefaultBatchConcurrency,
+		batchTimeout:     DefaultBatchTimeout,
+	}
+	for _, opt := range opts {
+		opt(handler)
 	}
 
 	handler.configureRoutes()
+	handler.configureAdminRoutes()
 
 	server := &http.Server{
 		Addr:         ":3000",           // configure the bind address with default port
 		Handler:      handler.r,         // set the default handler
 		ReadTimeout:  5 * time.Second,   // max time to read request from the client
-		WriteTimeout: 10 * time.Second,  // max time to write response to the client
+		WriteTimeout: WriteTimeout,      // max time to write response to the client
 		IdleTimeout:  120 * time.Second, // max time for connections using TCP Keep-Alive
 	}
 
+	admin := &http.Server{
+		Addr:         DefaultAdminAddr,
+		Handler:      handler.admin,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: WriteTimeout,
+		IdleTimeout:  120 * time.Second,
+	}
+
 	httpServer := &ServerHTTP{
-		svc: endorService,
-		srv: server,
-		h:   handler,
+		svc:   endorService,
+		srv:   server,
+		admin: admin,
+		h:     handler,
 	}
 
 	return httpServer
@@ -51,7 +138,19 @@ func (s *ServerHTTP) ListenAndServe(port string) error {
 	return s.srv.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the HTTP server.
+// ListenAndServeAdmin starts the listener of the administration of the fleet on the specified address.
+func (s *ServerHTTP) ListenAndServeAdmin(addr string) error {
+	if len(addr) != 0 {
+		s.admin.Addr = addr
+	}
+	return s.admin.ListenAndServe()
+}
+
+// Shutdown gracefully shuts down the HTTP server and the listener of the administration.
 func (s *ServerHTTP) Shutdown(ctx context.Context) error {
-	return s.srv.Shutdown(ctx)
+	adminErr := s.admin.Shutdown(ctx)
+	if err := s.srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	return adminErr
 }
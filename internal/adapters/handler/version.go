@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	// APIVersionV1 is the path prefix of the first version of the API.
+	APIVersionV1 = "/v1"
+
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+var (
+	// UnversionedDeprecatedAt is the date the unversioned routes were deprecated in favour of "/v1".
+	UnversionedDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	// DefaultUnversionedSunset is the date the unversioned routes will be removed.
+	DefaultUnversionedSunset = UnversionedDeprecatedAt.AddDate(0, 6, 0)
+)
+
+// versionPrefix matches the version of the API at the start of a path.
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+`)
+
+// Deprecated marks the routes as deprecated in favour of the same routes under the successor prefix.
+// The responses have the "Deprecation" (RFC 9745) and "Sunset" (RFC 8594) headers, and a "Link" to the successor route.
+func Deprecated(deprecatedAt time.Time, sunset time.Time, successor string) func(http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DeprecationHeader, deprecation)
+			w.Header().Set(SunsetHeader, sunsetDate)
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, r.URL.Path))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
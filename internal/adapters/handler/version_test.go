@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Generation: 1, Available: true}, nil
+		},
+	}
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := NewHTTPServer(services.NewEndorService([]adapters.IonCannon{cannon}), validator.New(), WithUnversionedSunset(sunset))
+
+	send := func(path string) *httptest.ResponseRecorder {
+		body := `{"protocols":["closest-enemies"],"scan":[{"coordinates":{"x":0,"y":40},"enemies":{"type":"soldier","number":10}}]}`
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.h.r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("versioned routes are not deprecated", func(t *testing.T) {
+		rec := send("/v1/attack/plan")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(DeprecationHeader))
+		assert.Empty(t, rec.Header().Get(SunsetHeader))
+	})
+
+	t.Run("unversioned routes are deprecated aliases", func(t *testing.T) {
+		versioned := send("/v1/attack/plan")
+		rec := send("/attack/plan")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "@1792368000", rec.Header().Get(DeprecationHeader))
+		assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", rec.Header().Get(SunsetHeader))
+		assert.Equal(t, `</v1/attack/plan>; rel="successor-version"`, rec.Header().Get("Link"))
+		assert.JSONEq(t, stripPlanID(versioned.Body.String()), stripPlanID(rec.Body.String()))
+	})
+
+	t.Run("unknown versions are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send("/v2/attack/plan").Code)
+	})
+}
+
+// stripPlanID removes the fields of the plans that change on every request.
+func stripPlanID(plan string) string {
+	var fields map[string]interface{}
+	json.Unmarshal([]byte(plan), &fields)
+	delete(fields, "id")
+	delete(fields, "expiresAt")
+	res, _ := json.Marshal(fields)
+	return string(res)
+}
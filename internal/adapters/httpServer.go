@@ -5,5 +5,6 @@ import "context"
 // ServerHTTP Interface for service to use
 type ServerHTTP interface {
 	ListenAndServe(port string) error
+	ListenAndServeAdmin(addr string) error
 	Shutdown(ctx context.Context) error
 }
@@ -1,9 +1,17 @@
 package adapters
 
-import "github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+import (
+	"context"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
 
 // IonCannon Interface for service to use
+// The context bounds the duration of the calls, implementations must return when it is done.
+// Close releases the connections and goroutines of the client once the Ion Cannon is no longer used.
 type IonCannon interface {
-	CheckStatus() (*domain.IonCannon, error)
-	FireCommand(targetX int, targetY int, enemies int) (casualties int, generation int, err error)
+	ID() string
+	CheckStatus(ctx context.Context) (*domain.IonCannon, error)
+	FireCommand(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error)
+	Close() error
 }
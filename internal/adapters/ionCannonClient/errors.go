@@ -0,0 +1,88 @@
+package ionCannonClient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// maxErrorBodySize is the maximum size of the error payloads read from the Ion Cannons.
+const maxErrorBodySize = 4 << 10
+
+// errorPayload is the error payload of the Ion Cannons. The error can be a message
+// or an object with the code and the message:
+//
+//	{"code": "recharging", "message": "ion cannon is recharging", "retryable": true}
+//	{"error": "ion cannon is recharging"}
+//	{"error": {"code": "recharging", "message": "ion cannon is recharging"}}
+type errorPayload struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Error     json.RawMessage `json:"error"`
+	Retryable *bool           `json:"retryable"`
+}
+
+// newCannonError parses the error response of an Ion Cannon. Payloads that are not JSON are used as the message,
+// and the code and retryability default to the ones of the status code.
+func newCannonError(op error, resp *http.Response) *domain.CannonError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		payload = errorPayload{Message: strings.TrimSpace(string(body))}
+	}
+	if len(payload.Error) > 0 {
+		var nested errorPayload
+		if err := json.Unmarshal(payload.Error, &nested.Message); err != nil {
+			_ = json.Unmarshal(payload.Error, &nested)
+		}
+		if payload.Code == "" {
+			payload.Code = nested.Code
+		}
+		if payload.Message == "" {
+			payload.Message = nested.Message
+		}
+	}
+
+	e := &domain.CannonError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Code:       payload.Code,
+		Message:    payload.Message,
+	}
+	if e.Code == "" {
+		e.Code = statusErrorCode(op, resp.StatusCode)
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	if payload.Retryable != nil {
+		e.Retryable = *payload.Retryable
+	} else {
+		e.Retryable = resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests ||
+			e.Code == domain.CannonErrorRecharging
+	}
+	return e
+}
+
+// statusErrorCode returns the error code matching the HTTP status of a response to the op.
+// Only the fire commands have a target, so a bad request of a status check has no specific code.
+func statusErrorCode(op error, status int) string {
+	switch {
+	case (status == http.StatusBadRequest || status == http.StatusUnprocessableEntity) && op == domain.ErrCannonFireFailed:
+		return domain.CannonErrorBadTarget
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return domain.CannonErrorUnauthorized
+	case status == http.StatusTooManyRequests:
+		return domain.CannonErrorRateLimited
+	case status == http.StatusServiceUnavailable:
+		return domain.CannonErrorUnavailable
+	case status >= http.StatusInternalServerError:
+		return domain.CannonErrorInternal
+	default:
+		return domain.CannonErrorUnknown
+	}
+}
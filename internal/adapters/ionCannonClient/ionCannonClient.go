@@ -2,57 +2,141 @@ package ionCannonClient
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/signature"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 )
 
 // IonCannonClient represents the Ion Cannon client.
-// [Improvement] add timeout to http client.
 type IonCannonClient struct {
 	BaseURL string
+	id      string
+	client  *http.Client
+
+	connectTimeout      time.Duration
+	responseTimeout     time.Duration
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	statusRetries       int
+	retryBackoff        time.Duration
+	userAgent           string
+	tlsConfig           *tls.Config
+	signingSecret       []byte
+	streaming           bool
+	streamIdleTimeout   time.Duration
+	stream              statusStream
+	transport           http.RoundTripper
+	middlewares         []func(http.RoundTripper) http.RoundTripper
 }
 
 // NewIonCannonClient creates a new instance of the IonCannonClient.
-func NewIonCannonClient(baseURL string) *IonCannonClient {
-	return &IonCannonClient{
-		BaseURL: baseURL,
+func NewIonCannonClient(baseURL string, opts ...Option) *IonCannonClient {
+	c := &IonCannonClient{
+		BaseURL:             baseURL,
+		id:                  baseURL,
+		connectTimeout:      DefaultConnectTimeout,
+		responseTimeout:     DefaultResponseTimeout,
+		maxIdleConns:        DefaultMaxIdleConns,
+		maxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		statusRetries:       DefaultStatusRetries,
+		retryBackoff:        DefaultRetryBackoff,
+		userAgent:           DefaultUserAgent,
+		streamIdleTimeout:   DefaultStreamIdleTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	transport := c.newTransport()
+	for _, middleware := range c.middlewares {
+		transport = middleware(transport)
 	}
+	c.client = &http.Client{Transport: transport}
+	return c
+}
+
+// ID returns the identifier of the Ion Cannon.
+func (c *IonCannonClient) ID() string {
+	return c.id
 }
 
 // CheckStatus sends an HTTP GET request to check the Ion Cannon status.
-func (c *IonCannonClient) CheckStatus() (*domain.IonCannon, error) {
+// Transport errors and retryable errors of the Ion Cannon are retried with a jittered exponential backoff.
+// With the status stream enabled, the last streamed status is returned instead while the stream is connected.
+func (c *IonCannonClient) CheckStatus(ctx context.Context) (*domain.IonCannon, error) {
+	if c.streaming {
+		c.startStream()
+		if status := c.stream.get(); status != nil {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return status, nil
+		}
+	}
+
 	url := c.BaseURL + "/status"
-	resp, err := http.Get(url)
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		status, retry, err := c.checkStatus(ctx, url)
+		if err == nil || !retry || attempt >= c.statusRetries || ctx.Err() != nil {
+			return status, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(time.Duration(rand.Int63n(int64(backoff) + 1))):
+		}
+		backoff *= 2
+	}
+}
+
+// checkStatus sends a single status request, it also returns whether the request can be retried.
+func (c *IonCannonClient) checkStatus(ctx context.Context, url string) (status *domain.IonCannon, retry bool, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, true, transportError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to check Ion Cannon status: %s", resp.Status)
+		cannonErr := newCannonError(domain.ErrCannonStatusFailed, resp)
+		return nil, cannonErr.Retryable, cannonErr
 	}
 
-	var status struct {
+	var res struct {
 		Generation int  `json:"generation"`
 		Available  bool `json:"available"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return nil, err
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, false, fmt.Errorf("%w: invalid response: %s", domain.ErrCannonStatusFailed, err)
 	}
 
-	res := &domain.IonCannon{
-		Generation: status.Generation,
-		Available:  status.Available,
+	status = &domain.IonCannon{
+		Generation: res.Generation,
+		Available:  res.Available,
 	}
 
-	return res, nil
+	return status, false, nil
 }
 
-// FireCommand sends an HTTP POST request to fire the Ion Cannon.
+// FireCommand sends an HTTP POST request to fire the Ion Cannon, signed if the client has a signing secret.
 func (c *IonCannonClient) FireCommand(
+	ctx context.Context,
 	targetX int,
 	targetY int,
 	enemies int,
@@ -70,14 +154,24 @@ func (c *IonCannonClient) FireCommand(
 		return 0, 0, err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(jsonBody))
+	req, err := c.newRequest(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
 	if err != nil {
 		return 0, 0, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.signingSecret != nil {
+		if err := signature.Sign(req, jsonBody, c.signingSecret); err != nil {
+			return 0, 0, err
+		}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, 0, transportError(err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("failed to fire Ion Cannon: %s", resp.Status)
+		return 0, 0, newCannonError(domain.ErrCannonFireFailed, resp)
 	}
 
 	var result struct {
@@ -85,8 +179,30 @@ func (c *IonCannonClient) FireCommand(
 		Generation int `json:"generation"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, 0, err
+		return 0, 0, fmt.Errorf("%w: invalid response: %s", domain.ErrCannonFireFailed, err)
 	}
 
+	c.stream.markFired()
 	return result.Casualties, result.Generation, nil
 }
+
+// newRequest creates a request to the Ion Cannon with the headers of the client.
+func (c *IonCannonClient) newRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return req, nil
+}
+
+// transportError wraps the errors of the HTTP client with the domain errors.
+func transportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %s", domain.ErrCannonTimeout, err)
+	}
+	return fmt.Errorf("%w: %s", domain.ErrCannonUnreachable, err)
+}
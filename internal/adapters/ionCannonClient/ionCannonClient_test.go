@@ -1,11 +1,20 @@
 package ionCannonClient
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/signature"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
 )
 
 func TestIonCannonClient_CheckStatus(t *testing.T) {
@@ -21,7 +30,7 @@ func TestIonCannonClient_CheckStatus(t *testing.T) {
 	defer server.Close()
 
 	client := NewIonCannonClient(server.URL)
-	status, err := client.CheckStatus()
+	status, err := client.CheckStatus(context.Background())
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -52,7 +61,7 @@ func TestIonCannonClient_FireCommand(t *testing.T) {
 	defer server.Close()
 
 	client := NewIonCannonClient(server.URL)
-	casualties, generation, err := client.FireCommand(0, 40, 1)
+	casualties, generation, err := client.FireCommand(context.Background(), 0, 40, 1)
 
 	// Check the results
 	if err != nil {
@@ -65,3 +74,385 @@ func TestIonCannonClient_FireCommand(t *testing.T) {
 		t.Errorf("Expected generation to be 1, got %d", generation)
 	}
 }
+
+func TestIonCannonClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	client := NewIonCannonClient(server.URL)
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonStatusFailed) {
+		t.Errorf("Expected status failed error, got %v", err)
+	}
+	if _, _, err := client.FireCommand(context.Background(), 0, 40, 1); !errors.Is(err, domain.ErrCannonFireFailed) {
+		t.Errorf("Expected fire failed error, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonUnreachable) {
+		t.Errorf("Expected unreachable error, got %v", err)
+	}
+}
+
+func TestIonCannonClient_CannonErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		checkStatus bool
+		expected    domain.CannonError
+	}{
+		{
+			name:     "code and message",
+			status:   http.StatusServiceUnavailable,
+			body:     `{"code": "recharging", "message": "ion cannon is recharging"}`,
+			expected: domain.CannonError{StatusCode: 503, Code: "recharging", Message: "ion cannon is recharging", Retryable: true},
+		},
+		{
+			name:     "error message",
+			status:   http.StatusBadRequest,
+			body:     `{"error": "invalid target"}`,
+			expected: domain.CannonError{StatusCode: 400, Code: "bad_target", Message: "invalid target"},
+		},
+		{
+			name:        "bad request of a status check",
+			status:      http.StatusBadRequest,
+			body:        `{"error": "invalid request"}`,
+			checkStatus: true,
+			expected:    domain.CannonError{StatusCode: 400, Code: "unknown", Message: "invalid request"},
+		},
+		{
+			name:     "error object",
+			status:   http.StatusConflict,
+			body:     `{"error": {"code": "recharging", "message": "cooling down"}, "retryable": false}`,
+			expected: domain.CannonError{StatusCode: 409, Code: "recharging", Message: "cooling down"},
+		},
+		{
+			name:     "plain text",
+			status:   http.StatusInternalServerError,
+			body:     "overheated\n",
+			expected: domain.CannonError{StatusCode: 500, Code: "internal", Message: "overheated", Retryable: true},
+		},
+		{
+			name:     "empty",
+			status:   http.StatusTeapot,
+			expected: domain.CannonError{StatusCode: 418, Code: "unknown", Message: "I'm a teapot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewIonCannonClient(server.URL)
+			var err error
+			tt.expected.Op = domain.ErrCannonFireFailed
+			if tt.checkStatus {
+				_, err = client.CheckStatus(context.Background())
+				tt.expected.Op = domain.ErrCannonStatusFailed
+			} else {
+				_, _, err = client.FireCommand(context.Background(), 0, 40, 1)
+			}
+			var cannonErr *domain.CannonError
+			if !errors.As(err, &cannonErr) {
+				t.Fatalf("Expected a CannonError, got %v", err)
+			}
+			if *cannonErr != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *cannonErr)
+			}
+		})
+	}
+}
+
+func TestIonCannonClient_Retries(t *testing.T) {
+	var statusCalls, fireCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "endor-test" {
+			t.Errorf("Expected User-Agent endor-test, got %s", ua)
+		}
+		if r.URL.Path == "/fire" {
+			atomic.AddInt32(&fireCalls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// The status fails twice before answering
+		if atomic.AddInt32(&statusCalls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"generation": 1, "available": true}`))
+	}))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithUserAgent("endor-test"), WithStatusRetries(2, time.Millisecond))
+
+	if _, err := client.CheckStatus(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if statusCalls != 3 {
+		t.Errorf("Expected 3 status calls, got %d", statusCalls)
+	}
+
+	// Fire commands are never retried
+	if _, _, err := client.FireCommand(context.Background(), 0, 40, 1); !errors.Is(err, domain.ErrCannonFireFailed) {
+		t.Errorf("Expected fire failed error, got %v", err)
+	}
+	if fireCalls != 1 {
+		t.Errorf("Expected 1 fire call, got %d", fireCalls)
+	}
+
+	// Client errors are not retried
+	atomic.StoreInt32(&statusCalls, 0)
+	client = NewIonCannonClient(server.URL+"/unknown", WithUserAgent("endor-test"), WithStatusRetries(0, time.Millisecond))
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonStatusFailed) {
+		t.Errorf("Expected status failed error, got %v", err)
+	}
+	if statusCalls != 1 {
+		t.Errorf("Expected 1 status call, got %d", statusCalls)
+	}
+}
+
+func TestIonCannonClient_Transport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"generation": 1, "available": true}`))
+	}))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithResponseTimeout(10*time.Millisecond), WithStatusRetries(0, 0))
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonTimeout) {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+
+	// Middlewares wrap the injected transport
+	var requests []string
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			return next.RoundTrip(r)
+		})
+	}
+	client = NewIonCannonClient(server.URL, WithTransport(http.DefaultTransport), WithTransportMiddleware(record))
+	if _, err := client.CheckStatus(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "GET /status" {
+		t.Errorf("Expected the status request to be recorded, got %v", requests)
+	}
+}
+
+func TestIonCannonClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"generation": 1, "available": true}`))
+	}))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithStatusRetries(0, 0))
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonUnreachable) {
+		t.Errorf("Expected unreachable error for an unknown certificate, got %v", err)
+	}
+
+	config := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client = NewIonCannonClient(server.URL, WithTLSConfig(config))
+	if _, err := client.CheckStatus(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestIonCannonClient_Signing(t *testing.T) {
+	secret := []byte("top-secret")
+	verifier := signature.NewVerifier(secret, signature.DefaultMaxSkew)
+	server := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"casualties": 1, "generation": 2}`))
+	})))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithSigningSecret(secret))
+	if _, _, err := client.FireCommand(context.Background(), 1, 2, 10); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	client = NewIonCannonClient(server.URL, WithSigningSecret([]byte("other-secret")))
+	if _, _, err := client.FireCommand(context.Background(), 1, 2, 10); !errors.Is(err, domain.ErrCannonFireFailed) {
+		t.Errorf("Expected fire error for a wrong secret, got %v", err)
+	}
+	client = NewIonCannonClient(server.URL)
+	if _, _, err := client.FireCommand(context.Background(), 1, 2, 10); !errors.Is(err, domain.ErrCannonFireFailed) {
+		t.Errorf("Expected fire error without signature, got %v", err)
+	}
+}
+
+func TestIonCannonClient_StatusStream(t *testing.T) {
+	var polls int32
+	events := make(chan string, 1)
+	events <- `{"generation": 1, "available": true}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			atomic.AddInt32(&polls, 1)
+			w.Write([]byte(`{"generation": 1, "available": false}`))
+		case "/status/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case data := <-events:
+					fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+					w.(http.Flusher).Flush()
+				}
+			}
+		case "/fire":
+			w.Write([]byte(`{"casualties": 1, "generation": 1}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithStatusStream())
+	defer client.Close()
+	waitForStatus(t, client, true)
+	polled := atomic.LoadInt32(&polls)
+
+	// The cannon is unavailable after firing until it streams its status
+	if _, _, err := client.FireCommand(context.Background(), 0, 40, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status, err := client.CheckStatus(context.Background()); err != nil || status.Available {
+		t.Errorf("Expected unavailable Ion Cannon after firing, got %+v, %v", status, err)
+	}
+	events <- `{"generation": 1, "available": true}`
+	waitForStatus(t, client, true)
+	if atomic.LoadInt32(&polls) != polled {
+		t.Errorf("Expected the status not to be polled while streaming")
+	}
+
+	// Ion Cannons without status stream are polled
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status/stream" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&polls, 1)
+		w.Write([]byte(`{"generation": 1, "available": true}`))
+	}))
+	defer server.Close()
+	client = NewIonCannonClient(server.URL, WithStatusStream())
+	defer client.Close()
+	atomic.StoreInt32(&polls, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := client.CheckStatus(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&polls); n != 3 {
+		t.Errorf("Expected 3 polls, got %d", n)
+	}
+}
+
+func TestIonCannonClient_StatusStreamIdle(t *testing.T) {
+	var polls, connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			atomic.AddInt32(&polls, 1)
+			w.Write([]byte(`{"generation": 1, "available": false}`))
+		case "/status/stream":
+			// The first connection streams the status, then hangs without heartbeats
+			w.Header().Set("Content-Type", "text/event-stream")
+			if atomic.AddInt32(&connections, 1) == 1 {
+				fmt.Fprint(w, ": heartbeat\n\nevent: status\ndata: {\"generation\": 1, \"available\": true}\n\n")
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	client := NewIonCannonClient(server.URL, WithStatusStream(), WithStreamIdleTimeout(100*time.Millisecond))
+	defer client.Close()
+	waitForStatus(t, client, true)
+
+	// The cached status is not returned once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.CheckStatus(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+
+	// The silent stream is reconnected and the status polled meanwhile
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&connections) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&connections); n < 2 {
+		t.Fatalf("Expected the stream to be reconnected, got %d connections", n)
+	}
+	status, err := client.CheckStatus(context.Background())
+	if err != nil || status.Available {
+		t.Errorf("Expected the polled status, got %+v, %v", status, err)
+	}
+	if atomic.LoadInt32(&polls) == 0 {
+		t.Errorf("Expected the status to be polled while the stream is idle")
+	}
+}
+
+// waitForStatus waits until the client returns the availability, checking it every few milliseconds.
+func waitForStatus(t *testing.T, client *IonCannonClient, available bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if status := client.stream.get(); status != nil && status.Available == available {
+			return
+		}
+		client.CheckStatus(context.Background())
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected the streamed availability to be %t", available)
+}
+
+// TestIonCannonClient_SimulatorCassette replays the traffic of a simulated ion cannon of generation 1, recorded with:
+//
+//	CANNON_GEN=1 PORT=3001 go run ./cmd/ion-cannon-sim
+//	RECORD_CASSETTES=1 go test ./internal/adapters/ionCannonClient -run Cassette
+//
+// The fixture checks the client against the simulator, it is not evidence of the behaviour of the real ion cannons.
+func TestIonCannonClient_SimulatorCassette(t *testing.T) {
+	cassette := mocks.NewCassette(t, "testdata/sim-ion-cannon-1.json")
+	client := NewIonCannonClient(cannonURL(), WithTransportMiddleware(cassette.Wrap))
+
+	status, err := client.CheckStatus(context.Background())
+	if err != nil || !status.Available || status.Generation != 1 {
+		t.Errorf("Expected available Ion Cannon of generation 1, got %+v, %v", status, err)
+	}
+	casualties, generation, err := client.FireCommand(context.Background(), 0, 40, 3)
+	if err != nil || casualties != 3 || generation != 1 {
+		t.Errorf("Expected 3 casualties of generation 1, got %d, %d, %v", casualties, generation, err)
+	}
+
+	// The Ion Cannon is recharging after firing
+	status, err = client.CheckStatus(context.Background())
+	if err != nil || status.Available {
+		t.Errorf("Expected unavailable Ion Cannon, got %+v, %v", status, err)
+	}
+	if _, _, err := client.FireCommand(context.Background(), 10, 20, 1); !errors.Is(err, domain.ErrCannonRecharging) {
+		t.Errorf("Expected recharging error, got %v", err)
+	}
+}
+
+// cannonURL returns the URL of the simulated ion cannon recorded by the cassettes.
+func cannonURL() string {
+	if url := os.Getenv("ION_CANNON_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:3001"
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
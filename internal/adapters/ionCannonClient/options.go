@@ -0,0 +1,144 @@
+package ionCannonClient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default configuration of the HTTP transport of the IonCannonClient.
+const (
+	DefaultConnectTimeout      = 2 * time.Second
+	DefaultResponseTimeout     = 10 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultStatusRetries       = 2
+	DefaultRetryBackoff        = 50 * time.Millisecond
+	DefaultUserAgent           = "endor-service"
+	// DefaultStreamIdleTimeout allows to miss a heartbeat of the status stream, sent every 15 seconds.
+	DefaultStreamIdleTimeout = 30 * time.Second
+)
+
+// Option configures the IonCannonClient.
+type Option func(*IonCannonClient)
+
+// WithID sets the identifier of the Ion Cannon. By default the base URL is used.
+func WithID(id string) Option {
+	return func(c *IonCannonClient) {
+		c.id = id
+	}
+}
+
+// WithConnectTimeout sets the maximum time to establish a connection with the Ion Cannon.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *IonCannonClient) {
+		c.connectTimeout = timeout
+	}
+}
+
+// WithResponseTimeout sets the maximum time to wait for the response headers once the request is sent.
+func WithResponseTimeout(timeout time.Duration) Option {
+	return func(c *IonCannonClient) {
+		c.responseTimeout = timeout
+	}
+}
+
+// WithMaxIdleConns sets the size of the pool of keep-alive connections, and the size per host.
+func WithMaxIdleConns(maxIdleConns int, maxIdleConnsPerHost int) Option {
+	return func(c *IonCannonClient) {
+		c.maxIdleConns = maxIdleConns
+		c.maxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+}
+
+// WithStatusRetries sets the number of times a failed status check is retried, waiting a random time
+// up to backoff before the first retry and doubling it on every retry.
+// Fire commands are never retried, as the Ion Cannon could have fired.
+func WithStatusRetries(retries int, backoff time.Duration) Option {
+	return func(c *IonCannonClient) {
+		c.statusRetries = retries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *IonCannonClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the connections with an Ion Cannon served over HTTPS,
+// e.g. a CA bundle or a client certificate for mutual TLS.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *IonCannonClient) {
+		c.tlsConfig = config
+	}
+}
+
+// WithSigningSecret signs the fire commands with the secret shared with the Ion Cannon,
+// which can check them with signature.Verifier.
+func WithSigningSecret(secret []byte) Option {
+	return func(c *IonCannonClient) {
+		c.signingSecret = secret
+	}
+}
+
+// WithStatusStream subscribes to the status stream of the Ion Cannon on the first status check,
+// so later checks return the last streamed status without sending a request. The status is polled
+// while the stream is disconnected, and always if the Ion Cannon does not serve the stream.
+// The stream is stopped by Close.
+func WithStatusStream() Option {
+	return func(c *IonCannonClient) {
+		c.streaming = true
+	}
+}
+
+// WithStreamIdleTimeout sets the maximum time without receiving an event or a heartbeat from the status stream.
+// The stream is reconnected afterwards, and the status polled meanwhile.
+func WithStreamIdleTimeout(timeout time.Duration) Option {
+	return func(c *IonCannonClient) {
+		c.streamIdleTimeout = timeout
+	}
+}
+
+// WithTransport sets the transport used to send the requests, replacing the default one.
+// The connect timeout, response timeout, keep-alive and TLS options are ignored.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *IonCannonClient) {
+		c.transport = transport
+	}
+}
+
+// WithTransportMiddleware wraps the transport, e.g. to log or record the requests.
+// Middlewares are applied in order, the last one receives the requests first.
+func WithTransportMiddleware(middleware func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *IonCannonClient) {
+		c.middlewares = append(c.middlewares, middleware)
+	}
+}
+
+// newTransport creates the HTTP transport with the options of the client.
+func (c *IonCannonClient) newTransport() http.RoundTripper {
+	if c.transport != nil {
+		return c.transport
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   c.connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       c.tlsConfig,
+		TLSHandshakeTimeout:   c.connectTimeout,
+		ResponseHeaderTimeout: c.responseTimeout,
+		MaxIdleConns:          c.maxIdleConns,
+		MaxIdleConnsPerHost:   c.maxIdleConnsPerHost,
+		IdleConnTimeout:       DefaultIdleConnTimeout,
+		ForceAttemptHTTP2:     true,
+	}
+}
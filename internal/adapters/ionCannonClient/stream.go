@@ -0,0 +1,171 @@
+package ionCannonClient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// maxStreamBackoff is the maximum time waited before reconnecting to the status stream.
+const maxStreamBackoff = 30 * time.Second
+
+// errStreamUnsupported is returned when the Ion Cannon does not serve the status stream.
+var errStreamUnsupported = errors.New("status stream not supported")
+
+// statusStream holds the last status received from the Server-Sent Events stream of an Ion Cannon.
+type statusStream struct {
+	start  sync.Once
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	status *domain.IonCannon // nil while the stream is not connected
+}
+
+// get returns the last streamed status, or nil if the stream is not connected.
+func (s *statusStream) get() *domain.IonCannon {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.status == nil {
+		return nil
+	}
+	status := *s.status
+	return &status
+}
+
+func (s *statusStream) set(status *domain.IonCannon) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// markFired makes the streamed status unavailable until the Ion Cannon streams its new status,
+// as the cannon recharges after firing.
+func (s *statusStream) markFired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != nil {
+		s.status.Available = false
+	}
+}
+
+// startStream subscribes to the status stream the first time it is called.
+func (c *IonCannonClient) startStream() {
+	c.stream.start.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.stream.cancel = cancel
+		go c.watchStatus(ctx)
+	})
+}
+
+// watchStatus keeps the status stream connected, reconnecting with an exponential backoff.
+// It stops if the Ion Cannon does not support streaming, so its status is always polled.
+func (c *IonCannonClient) watchStatus(ctx context.Context) {
+	backoff := c.retryBackoff
+	for {
+		connected, err := c.streamStatus(ctx)
+		c.stream.set(nil)
+		if errors.Is(err, errStreamUnsupported) || ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = c.retryBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxStreamBackoff || backoff <= 0 {
+			backoff = maxStreamBackoff
+		}
+	}
+}
+
+// streamStatus reads the status events of the stream until it is closed. It returns whether the stream was connected.
+// Events are in the Server-Sent Events format, with the same data as the /status endpoint:
+//
+//	event: status
+//	data: {"generation": 1, "available": true}
+//
+// The stream is closed if no line, including the heartbeat comments, is received within the idle timeout,
+// as the connection with the Ion Cannon may be lost without being closed.
+func (c *IonCannonClient) streamStatus(ctx context.Context) (bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := c.newRequest(streamCtx, http.MethodGet, c.BaseURL+"/status/stream", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed ||
+		resp.StatusCode == http.StatusNotImplemented:
+		return false, errStreamUnsupported
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("status stream: %s", resp.Status)
+	case mediaType != "text/event-stream":
+		return false, errStreamUnsupported
+	}
+
+	idle := time.AfterFunc(c.streamIdleTimeout, cancel)
+	defer idle.Stop()
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		idle.Reset(c.streamIdleTimeout)
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// Events are dispatched on blank lines
+			if data.Len() > 0 {
+				var status struct {
+					Generation int  `json:"generation"`
+					Available  bool `json:"available"`
+				}
+				if err := json.Unmarshal([]byte(data.String()), &status); err == nil {
+					c.stream.set(&domain.IonCannon{Generation: status.Generation, Available: status.Available})
+				}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if streamCtx.Err() != nil && ctx.Err() == nil {
+		return true, fmt.Errorf("status stream: no event for %s", c.streamIdleTimeout)
+	}
+	return true, scanner.Err()
+}
+
+// Close stops the status stream of the Ion Cannon, if any, and closes the idle connections.
+// Status checks are polled afterwards.
+func (c *IonCannonClient) Close() error {
+	c.stream.start.Do(func() {})
+	if c.stream.cancel != nil {
+		c.stream.cancel()
+	}
+	c.client.CloseIdleConnections()
+	return nil
+}
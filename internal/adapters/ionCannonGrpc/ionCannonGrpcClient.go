@@ -0,0 +1,163 @@
+package ionCannonGrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonGrpc/pb"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// DefaultFireTimeout is the maximum time to wait for the answer of a fire command, as the fire commands
+// are not bounded by the context of the caller.
+const DefaultFireTimeout = 10 * time.Second
+
+// IonCannonGrpcClient represents the client of the Ion Cannons exposing the gRPC API.
+type IonCannonGrpcClient struct {
+	Target      string
+	id          string
+	dialOpts    []grpc.DialOption
+	fireTimeout time.Duration
+	conn        *grpc.ClientConn
+	client      pb.IonCannonClient
+}
+
+// Option configures the IonCannonGrpcClient.
+type Option func(*IonCannonGrpcClient)
+
+// WithID sets the identifier of the Ion Cannon. By default the target is used.
+func WithID(id string) Option {
+	return func(c *IonCannonGrpcClient) {
+		c.id = id
+	}
+}
+
+// WithFireTimeout sets the maximum time to wait for the answer of a fire command.
+func WithFireTimeout(timeout time.Duration) Option {
+	return func(c *IonCannonGrpcClient) {
+		c.fireTimeout = timeout
+	}
+}
+
+// WithDialOptions adds options to the gRPC connection, e.g. the transport credentials or a custom dialer.
+// By default the connection is not encrypted.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *IonCannonGrpcClient) {
+		c.dialOpts = append(c.dialOpts, opts...)
+	}
+}
+
+// WithTLSConfig secures the connection with TLS, e.g. with a CA bundle or a client certificate for mutual TLS.
+func WithTLSConfig(config *tls.Config) Option {
+	return WithDialOptions(grpc.WithTransportCredentials(credentials.NewTLS(config)))
+}
+
+// NewIonCannonGrpcClient creates a new instance of the IonCannonGrpcClient.
+// The target uses the gRPC name syntax, e.g. "ion-cannon-4:50051". The connection is established
+// lazily on the first call and reestablished if it is lost.
+func NewIonCannonGrpcClient(target string, opts ...Option) (*IonCannonGrpcClient, error) {
+	c := &IonCannonGrpcClient{
+		Target:      target,
+		id:          target,
+		dialOpts:    []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		fireTimeout: DefaultFireTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn, err := grpc.NewClient(target, c.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client of %s: %w", target, err)
+	}
+	c.conn = conn
+	c.client = pb.NewIonCannonClient(conn)
+	return c, nil
+}
+
+// ID returns the identifier of the Ion Cannon.
+func (c *IonCannonGrpcClient) ID() string {
+	return c.id
+}
+
+// Close closes the connection with the Ion Cannon.
+func (c *IonCannonGrpcClient) Close() error {
+	return c.conn.Close()
+}
+
+// CheckStatus calls the Status method of the Ion Cannon.
+func (c *IonCannonGrpcClient) CheckStatus(ctx context.Context) (*domain.IonCannon, error) {
+	// Wait for the connection to be ready instead of failing fast, bounded by the context.
+	res, err := c.client.Status(ctx, &pb.StatusRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, callError(err, domain.ErrCannonStatusFailed)
+	}
+
+	return &domain.IonCannon{
+		Generation: int(res.GetGeneration()),
+		Available:  res.GetAvailable(),
+	}, nil
+}
+
+// FireCommand calls the Fire method of the Ion Cannon. An Ion Cannon not answering within the fire timeout
+// returns ErrCannonTimeout, even if it received the command.
+func (c *IonCannonGrpcClient) FireCommand(
+	ctx context.Context,
+	targetX int,
+	targetY int,
+	enemies int,
+) (casualties int, generation int, err error) {
+	req := &pb.FireRequest{
+		Target: &pb.Target{
+			X: int32(targetX),
+			Y: int32(targetY),
+		},
+		Enemies: int32(enemies),
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.fireTimeout)
+	defer cancel()
+	res, err := c.client.Fire(ctx, req)
+	if err != nil {
+		return 0, 0, callError(err, domain.ErrCannonFireFailed)
+	}
+
+	return int(res.GetCasualties()), int(res.GetGeneration()), nil
+}
+
+// callError wraps the gRPC errors with the domain errors. Errors returned by the Ion Cannon are
+// CannonErrors wrapping the failed error, with a code matching their gRPC code.
+func callError(err error, failed error) error {
+	s := status.Convert(err)
+	switch s.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", domain.ErrCannonTimeout, s.Message())
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", domain.ErrCannonUnreachable, s.Message())
+	}
+
+	cannonErr := &domain.CannonError{Op: failed, Code: domain.CannonErrorUnknown, Message: s.Message()}
+	switch s.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		// Only the fire commands have a target
+		if failed == domain.ErrCannonFireFailed {
+			cannonErr.Code = domain.CannonErrorBadTarget
+		}
+	case codes.FailedPrecondition:
+		cannonErr.Code, cannonErr.Retryable = domain.CannonErrorRecharging, true
+	case codes.Unauthenticated, codes.PermissionDenied:
+		cannonErr.Code = domain.CannonErrorUnauthorized
+	case codes.ResourceExhausted:
+		cannonErr.Code, cannonErr.Retryable = domain.CannonErrorRateLimited, true
+	case codes.Internal, codes.Unknown, codes.Aborted:
+		cannonErr.Code, cannonErr.Retryable = domain.CannonErrorInternal, true
+	}
+	return cannonErr
+}
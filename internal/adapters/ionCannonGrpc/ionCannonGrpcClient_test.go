@@ -0,0 +1,120 @@
+package ionCannonGrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonGrpc/pb"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+)
+
+func TestIonCannonGrpcClient(t *testing.T) {
+	server := &mocks.IonCannonGrpcServerMock{
+		StatusFunc: func(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+			return &pb.StatusResponse{Generation: 2, Available: true}, nil
+		},
+		FireFunc: func(ctx context.Context, req *pb.FireRequest) (*pb.FireResponse, error) {
+			if req.GetTarget().GetX() != 0 || req.GetTarget().GetY() != 40 {
+				t.Errorf("Expected target (0, 40), got (%d, %d)", req.GetTarget().GetX(), req.GetTarget().GetY())
+			}
+			return &pb.FireResponse{Casualties: req.GetEnemies(), Generation: 2}, nil
+		},
+	}
+	dialer, stop := server.Serve()
+	defer stop()
+
+	client, err := NewIonCannonGrpcClient("passthrough:///ion-cannon", WithID("cannon-4"), WithDialOptions(dialer))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	if client.ID() != "cannon-4" {
+		t.Errorf("Expected ID cannon-4, got %s", client.ID())
+	}
+
+	status, err := client.CheckStatus(context.Background())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !status.Available || status.Generation != 2 {
+		t.Errorf("Expected available Ion Cannon of generation 2, got %+v", status)
+	}
+
+	casualties, generation, err := client.FireCommand(context.Background(), 0, 40, 5)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if casualties != 5 || generation != 2 {
+		t.Errorf("Expected 5 casualties of generation 2, got %d and %d", casualties, generation)
+	}
+}
+
+func TestIonCannonGrpcClient_Errors(t *testing.T) {
+	server := &mocks.IonCannonGrpcServerMock{
+		StatusFunc: func(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+			return nil, status.Error(codes.Internal, "capacitor failure")
+		},
+		FireFunc: func(ctx context.Context, req *pb.FireRequest) (*pb.FireResponse, error) {
+			return nil, status.Error(codes.FailedPrecondition, "recharging")
+		},
+	}
+	dialer, stop := server.Serve()
+
+	client, err := NewIonCannonGrpcClient("passthrough:///ion-cannon", WithDialOptions(dialer))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.CheckStatus(context.Background()); !errors.Is(err, domain.ErrCannonStatusFailed) {
+		t.Errorf("Expected status failed error, got %v", err)
+	}
+	_, _, err = client.FireCommand(context.Background(), 0, 40, 5)
+	if !errors.Is(err, domain.ErrCannonFireFailed) || !errors.Is(err, domain.ErrCannonRecharging) {
+		t.Errorf("Expected fire failed error of a recharging cannon, got %v", err)
+	}
+
+	// Status checks wait for the Ion Cannon to be reachable until the deadline
+	stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.CheckStatus(ctx); !errors.Is(err, domain.ErrCannonTimeout) {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if _, _, err := client.FireCommand(context.Background(), 0, 40, 5); !errors.Is(err, domain.ErrCannonUnreachable) {
+		t.Errorf("Expected unreachable error, got %v", err)
+	}
+}
+
+func TestIonCannonGrpcClient_FireTimeout(t *testing.T) {
+	server := &mocks.IonCannonGrpcServerMock{
+		FireFunc: func(ctx context.Context, req *pb.FireRequest) (*pb.FireResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	dialer, stop := server.Serve()
+	defer stop()
+
+	client, err := NewIonCannonGrpcClient("passthrough:///ion-cannon", WithDialOptions(dialer), WithFireTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	// The fire commands are bounded by the fire timeout even without a deadline
+	start := time.Now()
+	if _, _, err := client.FireCommand(context.Background(), 0, 40, 5); !errors.Is(err, domain.ErrCannonTimeout) {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the fire command to time out after 50ms, took %s", elapsed)
+	}
+}
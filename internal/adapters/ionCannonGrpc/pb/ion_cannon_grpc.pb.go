@@ -0,0 +1,150 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: ion_cannon.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	IonCannon_Status_FullMethodName = "/endor.ioncannon.v1.IonCannon/Status"
+	IonCannon_Fire_FullMethodName   = "/endor.ioncannon.v1.IonCannon/Fire"
+)
+
+// IonCannonClient is the client API for IonCannon service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IonCannonClient interface {
+	// Status returns the generation of the ion cannon and whether it is ready to fire.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Fire fires the ion cannon at the target and returns the casualties.
+	Fire(ctx context.Context, in *FireRequest, opts ...grpc.CallOption) (*FireResponse, error)
+}
+
+type ionCannonClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIonCannonClient(cc grpc.ClientConnInterface) IonCannonClient {
+	return &ionCannonClient{cc}
+}
+
+func (c *ionCannonClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, IonCannon_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ionCannonClient) Fire(ctx context.Context, in *FireRequest, opts ...grpc.CallOption) (*FireResponse, error) {
+	out := new(FireResponse)
+	err := c.cc.Invoke(ctx, IonCannon_Fire_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IonCannonServer is the server API for IonCannon service.
+// All implementations must embed UnimplementedIonCannonServer
+// for forward compatibility
+type IonCannonServer interface {
+	// Status returns the generation of the ion cannon and whether it is ready to fire.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Fire fires the ion cannon at the target and returns the casualties.
+	Fire(context.Context, *FireRequest) (*FireResponse, error)
+	mustEmbedUnimplementedIonCannonServer()
+}
+
+// UnimplementedIonCannonServer must be embedded to have forward compatible implementations.
+type UnimplementedIonCannonServer struct {
+}
+
+func (UnimplementedIonCannonServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedIonCannonServer) Fire(context.Context, *FireRequest) (*FireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fire not implemented")
+}
+func (UnimplementedIonCannonServer) mustEmbedUnimplementedIonCannonServer() {}
+
+// UnsafeIonCannonServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IonCannonServer will
+// result in compilation errors.
+type UnsafeIonCannonServer interface {
+	mustEmbedUnimplementedIonCannonServer()
+}
+
+func RegisterIonCannonServer(s grpc.ServiceRegistrar, srv IonCannonServer) {
+	s.RegisterService(&IonCannon_ServiceDesc, srv)
+}
+
+func _IonCannon_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IonCannonServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IonCannon_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IonCannonServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IonCannon_Fire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IonCannonServer).Fire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IonCannon_Fire_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IonCannonServer).Fire(ctx, req.(*FireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IonCannon_ServiceDesc is the grpc.ServiceDesc for IonCannon service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IonCannon_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "endor.ioncannon.v1.IonCannon",
+	HandlerType: (*IonCannonServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Status",
+			Handler:    _IonCannon_Status_Handler,
+		},
+		{
+			MethodName: "Fire",
+			Handler:    _IonCannon_Fire_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ion_cannon.proto",
+}
@@ -0,0 +1,11 @@
+package adapters
+
+import "github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+
+// JobStore Interface for service to persist asynchronous attack jobs
+type JobStore interface {
+	Save(job *domain.Job) error
+	Get(id string) (*domain.Job, error)
+	List() ([]*domain.Job, error)
+	Delete(id string) error
+}
@@ -0,0 +1,274 @@
+package jobStore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// FileJobStore persists every job as a JSON file in a local directory.
+type FileJobStore struct {
+	dir string
+	log *zap.SugaredLogger
+}
+
+// Option configures the FileJobStore.
+type Option func(*FileJobStore)
+
+// WithLogger sets the logger reporting the files of the directory that are not jobs.
+func WithLogger(log *zap.SugaredLogger) Option {
+	return func(s *FileJobStore) {
+		s.log = log
+	}
+}
+
+// NewFileJobStore creates a new instance of the FileJobStore, creating the directory if needed.
+func NewFileJobStore(dir string, opts ...Option) (*FileJobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+	s := &FileJobStore{dir: dir, log: zap.NewNop().Sugar()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Save writes the job to disk. The file is replaced atomically so a crash never leaves a partial job.
+func (s *FileJobStore) Save(job *domain.Job) error {
+	data, err := json.Marshal(newJobRecord(job))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, job.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// The data must be on disk before the rename, or a crash could leave an empty job
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
+
+// Get reads the job with the given ID from disk.
+func (s *FileJobStore) Get(id string) (*domain.Job, error) {
+	if !validID(id) {
+		return nil, domain.ErrJobNotFound
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record jobRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	if record.ID != id {
+		return nil, fmt.Errorf("failed to decode job %s: the file holds job %q", id, record.ID)
+	}
+	return record.toDomain(), nil
+}
+
+// List reads all the jobs stored on disk. The files that are not jobs, e.g. corrupted ones, are logged and skipped,
+// so they do not prevent the service from starting.
+func (s *FileJobStore) List() ([]*domain.Job, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []*domain.Job{}
+	for _, file := range files {
+		job, err := s.Get(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			s.log.Warnf("Skipping job file %s: %v\n", file, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Delete removes the job with the given ID from disk.
+func (s *FileJobStore) Delete(id string) error {
+	if !validID(id) {
+		return domain.ErrJobNotFound
+	}
+
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.ErrJobNotFound
+	}
+	return err
+}
+
+// validID returns false for the IDs that could point outside the directory.
+// IDs are generated by the service, anything else is not a job we know about.
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\.`)
+}
+
+func (s *FileJobStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// jobRecord is the representation of a job on disk.
+type jobRecord struct {
+	ID        string           `json:"id"`
+	Status    domain.JobStatus `json:"status"`
+	Radar     *radarRecord     `json:"radar"`
+	Report    *reportRecord    `json:"report,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	ErrorCode string           `json:"errorCode,omitempty"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+type radarRecord struct {
+	Protocols []domain.ProtocolType `json:"protocols"`
+	Scan      []*scanRecord         `json:"scan"`
+	MaxWait   time.Duration         `json:"maxWait,omitempty"`
+}
+
+type scanRecord struct {
+	X           int              `json:"x"`
+	Y           int              `json:"y"`
+	EnemyType   domain.EnemyType `json:"enemyType"`
+	EnemyNumber int              `json:"enemyNumber"`
+	Allies      int              `json:"allies"`
+}
+
+type reportRecord struct {
+	X          int              `json:"x"`
+	Y          int              `json:"y"`
+	EnemyType  domain.EnemyType `json:"enemyType"`
+	Casualties int              `json:"casualties"`
+	Generation int              `json:"generation"`
+	CannonID   string           `json:"cannonId"`
+	Candidates int              `json:"candidates"`
+	Pipeline   []string         `json:"pipeline"`
+	Timings    timingsRecord    `json:"timings"`
+	WaitTime   time.Duration    `json:"waitTime,omitempty"`
+
+	ExpectedCasualties float64 `json:"expectedCasualties"`
+	CasualtyDeviation  float64 `json:"casualtyDeviation"`
+	Anomalous          bool    `json:"anomalous"`
+}
+
+type timingsRecord struct {
+	Targeting   time.Duration `json:"targeting"`
+	StatusCheck time.Duration `json:"statusCheck"`
+	Fire        time.Duration `json:"fire"`
+	Total       time.Duration `json:"total"`
+}
+
+func newJobRecord(job *domain.Job) *jobRecord {
+	record := &jobRecord{
+		ID:        job.ID,
+		Status:    job.Status,
+		Error:     job.Error,
+		ErrorCode: job.ErrorCode,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	if job.Radar != nil {
+		record.Radar = &radarRecord{Protocols: job.Radar.Protocols, MaxWait: job.Radar.MaxWait}
+		for _, scan := range job.Radar.Scan {
+			record.Radar.Scan = append(record.Radar.Scan, &scanRecord{
+				X:           scan.Coordinates.X,
+				Y:           scan.Coordinates.Y,
+				EnemyType:   scan.Enemies.Type,
+				EnemyNumber: scan.Enemies.Number,
+				Allies:      scan.Allies,
+			})
+		}
+	}
+
+	if job.Report != nil {
+		record.Report = &reportRecord{
+			X:          job.Report.Target.X,
+			Y:          job.Report.Target.Y,
+			EnemyType:  job.Report.EnemyType,
+			Casualties: job.Report.Casualties,
+			Generation: job.Report.Generation,
+			CannonID:   job.Report.CannonID,
+			Candidates: job.Report.Candidates,
+			Pipeline:   job.Report.Pipeline,
+			Timings:    timingsRecord(job.Report.Timings),
+			WaitTime:   job.Report.WaitTime,
+
+			ExpectedCasualties: job.Report.ExpectedCasualties,
+			CasualtyDeviation:  job.Report.CasualtyDeviation,
+			Anomalous:          job.Report.Anomalous,
+		}
+	}
+	return record
+}
+
+func (r *jobRecord) toDomain() *domain.Job {
+	job := &domain.Job{
+		ID:        r.ID,
+		Status:    r.Status,
+		Error:     r.Error,
+		ErrorCode: r.ErrorCode,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+
+	if r.Radar != nil {
+		job.Radar = &domain.Radar{Protocols: r.Radar.Protocols, MaxWait: r.Radar.MaxWait}
+		for _, scan := range r.Radar.Scan {
+			job.Radar.Scan = append(job.Radar.Scan, &domain.Scan{
+				Coordinates: domain.NewCoordinates(scan.X, scan.Y),
+				Enemies:     &domain.Enemy{Type: scan.EnemyType, Number: scan.EnemyNumber},
+				Allies:      scan.Allies,
+			})
+		}
+	}
+
+	if r.Report != nil {
+		job.Report = &domain.Report{
+			Target:     domain.NewCoordinates(r.Report.X, r.Report.Y),
+			EnemyType:  r.Report.EnemyType,
+			Casualties: r.Report.Casualties,
+			Generation: r.Report.Generation,
+			CannonID:   r.Report.CannonID,
+			Candidates: r.Report.Candidates,
+			Pipeline:   r.Report.Pipeline,
+			Timings:    domain.Timings(r.Report.Timings),
+			WaitTime:   r.Report.WaitTime,
+
+			ExpectedCasualties: r.Report.ExpectedCasualties,
+			CasualtyDeviation:  r.Report.CasualtyDeviation,
+			Anomalous:          r.Report.Anomalous,
+		}
+	}
+	return job
+}
@@ -0,0 +1,90 @@
+package jobStore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileJobStore(t *testing.T) {
+	store, err := NewFileJobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	job := &domain.Job{
+		ID:     "a1b2",
+		Status: domain.JobSucceeded,
+		Radar: &domain.Radar{
+			Protocols: []domain.ProtocolType{domain.AvoidMech},
+			Scan: []*domain.Scan{
+				{Coordinates: domain.NewCoordinates(0, 40), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 10}, Allies: 2},
+			},
+		},
+		Report: &domain.Report{
+			Target:     domain.NewCoordinates(0, 40),
+			EnemyType:  domain.Soldier,
+			Casualties: 10,
+			Generation: 1,
+			CannonID:   "http://localhost:3001",
+			Candidates: 3,
+			Pipeline:   []string{"distance-limit:100", "avoid-mech"},
+			Timings:    domain.Timings{Targeting: time.Microsecond, StatusCheck: time.Millisecond, Fire: time.Second, Total: 2 * time.Second},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	assert.NoError(t, store.Save(job))
+
+	got, err := store.Get(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, job, got)
+
+	jobs, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	_, err = store.Get("unknown")
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+	_, err = store.Get("../a1b2")
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+
+	failed := &domain.Job{ID: "c3d4", Status: domain.JobFailed, Error: "no ion cannon available", ErrorCode: "no_cannon_available", CreatedAt: now, UpdatedAt: now}
+	assert.NoError(t, store.Save(failed))
+	got, err = store.Get(failed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, failed, got)
+
+	assert.NoError(t, store.Delete(job.ID))
+	_, err = store.Get(job.ID)
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+	assert.ErrorIs(t, store.Delete(job.ID), domain.ErrJobNotFound)
+	assert.ErrorIs(t, store.Delete("../c3d4"), domain.ErrJobNotFound)
+	jobs, err = store.List()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestFileJobStore_SkipsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileJobStore(dir)
+	assert.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, store.Save(&domain.Job{ID: "a1b2", Status: domain.JobPending, CreatedAt: now, UpdatedAt: now}))
+
+	// Truncated, empty and unrelated files are not jobs
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c3d4.json"), []byte(`{"id": "c3d4", "sta`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "e5f6.json"), nil, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "endor"}`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "backup.2024.json"), []byte(`{}`), 0o644))
+
+	jobs, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "a1b2", jobs[0].ID)
+}
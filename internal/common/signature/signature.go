@@ -0,0 +1,161 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers of the signed requests.
+const (
+	TimestampHeader = "X-Endor-Timestamp" // Unix time in seconds when the request was signed
+	NonceHeader     = "X-Endor-Nonce"     // random value making every signature unique
+	SignatureHeader = "X-Endor-Signature" // hex encoded HMAC-SHA256 of the request
+)
+
+// DefaultMaxSkew is the default maximum difference between the timestamp of a request and the time it is verified.
+const DefaultMaxSkew = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("missing request signature")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrExpiredSignature = errors.New("expired request signature")
+	ErrReplayedNonce    = errors.New("replayed request nonce")
+)
+
+// Sign signs the request with the secret, setting the timestamp, nonce and signature headers.
+// The body must be the body of the request, as it is part of the signature.
+func Sign(req *http.Request, body []byte, secret []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonceHex := hex.EncodeToString(nonce)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(NonceHeader, nonceHex)
+	req.Header.Set(SignatureHeader, compute(secret, req.Method, req.URL.RequestURI(), timestamp, nonceHex, body))
+	return nil
+}
+
+// compute returns the HMAC of the method, path, timestamp, nonce and body of a request.
+func compute(secret []byte, method string, path string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%x", method, path, timestamp, nonce, bodyHash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier checks the signature of the requests received by an ion cannon.
+// Requests older than the maximum skew or reusing a nonce are rejected, so they cannot be replayed.
+type Verifier struct {
+	secret  []byte
+	maxSkew time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // nonces seen and when they can be forgotten
+}
+
+// NewVerifier creates a verifier of the requests signed with the secret.
+func NewVerifier(secret []byte, maxSkew time.Duration) *Verifier {
+	return &Verifier{
+		secret:  secret,
+		maxSkew: maxSkew,
+		nonces:  map[string]time.Time{},
+	}
+}
+
+// Verify checks the signature of the request. The body is read and replaced, so it can still be read by the handler.
+func (v *Verifier) Verify(r *http.Request) error {
+	timestamp := r.Header.Get(TimestampHeader)
+	nonce := r.Header.Get(NonceHeader)
+	signature := r.Header.Get(SignatureHeader)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	now := time.Now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return ErrExpiredSignature
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		r.Body.Close()
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected := compute(v.secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrInvalidSignature
+	}
+
+	// The nonce is only recorded once the signature is valid, so forged requests cannot fill the cache
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for n, expiresAt := range v.nonces {
+		if now.After(expiresAt) {
+			delete(v.nonces, n)
+		}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return ErrReplayedNonce
+	}
+	v.nonces[nonce] = signedAt.Add(v.maxSkew)
+	return nil
+}
+
+// Middleware rejects the requests without a valid signature with a 401 Unauthorized response.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoadSecret reads a secret from a file, or from an environment variable if file is empty.
+// Trailing new lines of the file are ignored.
+func LoadSecret(file string, env string) ([]byte, error) {
+	var secret string
+	switch {
+	case file != "" && env != "":
+		return nil, errors.New("the secret must be read from a file or an environment variable, not both")
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret: %w", err)
+		}
+		secret = strings.TrimRight(string(data), "\r\n")
+	case env != "":
+		secret = os.Getenv(env)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("empty secret %s%s", file, env)
+	}
+	return []byte(secret), nil
+}
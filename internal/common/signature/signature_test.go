@@ -0,0 +1,112 @@
+package signature
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifier(t *testing.T) {
+	secret := []byte("top-secret")
+	body := `{"target":{"x":1,"y":2},"enemies":10}`
+	newRequest := func(t *testing.T, secret []byte) *http.Request {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://ion-cannon/fire", strings.NewReader(body))
+		if err := Sign(req, []byte(body), secret); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return req
+	}
+	verifier := NewVerifier(secret, time.Minute)
+
+	req := newRequest(t, secret)
+	if err := verifier.Verify(req); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if data, _ := io.ReadAll(req.Body); string(data) != body {
+		t.Errorf("Expected the body to be readable after the verification, got %q", data)
+	}
+
+	// A request cannot be replayed
+	replayed := newRequest(t, secret)
+	if err := verifier.Verify(replayed); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := verifier.Verify(replayed); !errors.Is(err, ErrReplayedNonce) {
+		t.Errorf("Expected replayed nonce error, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(r *http.Request)
+		err    error
+	}{
+		{"missing signature", func(r *http.Request) { r.Header.Del(SignatureHeader) }, ErrMissingSignature},
+		{"changed body", func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"enemies":1}`)) }, ErrInvalidSignature},
+		{"changed path", func(r *http.Request) { r.URL.Path = "/status" }, ErrInvalidSignature},
+		{"changed method", func(r *http.Request) { r.Method = http.MethodPut }, ErrInvalidSignature},
+		{"changed nonce", func(r *http.Request) { r.Header.Set(NonceHeader, "0000") }, ErrInvalidSignature},
+		{"expired", func(r *http.Request) { r.Header.Set(TimestampHeader, "1000") }, ErrExpiredSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, secret)
+			tt.change(req)
+			if err := verifier.Verify(req); !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if err := verifier.Verify(newRequest(t, []byte("other-secret"))); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected invalid signature error, got %v", err)
+	}
+}
+
+func TestVerifier_Middleware(t *testing.T) {
+	secret := []byte("top-secret")
+	handler := NewVerifier(secret, time.Minute).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/fire", nil)
+	if err := Sign(req, nil, secret); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestLoadSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CANNON_SECRET", "from-env")
+
+	if secret, err := LoadSecret(path, ""); err != nil || string(secret) != "from-file" {
+		t.Errorf("Expected the secret of the file, got %q, %v", secret, err)
+	}
+	if secret, err := LoadSecret("", "CANNON_SECRET"); err != nil || string(secret) != "from-env" {
+		t.Errorf("Expected the secret of the environment, got %q, %v", secret, err)
+	}
+	if _, err := LoadSecret(path, "CANNON_SECRET"); err == nil {
+		t.Errorf("Expected an error with a file and an environment variable")
+	}
+	if _, err := LoadSecret("", "MISSING_CANNON_SECRET"); err == nil {
+		t.Errorf("Expected an error for an empty secret")
+	}
+}
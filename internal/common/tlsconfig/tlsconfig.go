@@ -0,0 +1,213 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Files holds the files securing a TLS connection with a server.
+type Files struct {
+	CAFile     string // CA bundle verifying the certificate of the server, the system pool is used if empty
+	CertFile   string // client certificate, for mutual TLS
+	KeyFile    string // key of the client certificate
+	ServerName string // overrides the name verified in the certificate of the server
+}
+
+// NewClientConfig creates a client TLS configuration from the files, for the server dialled at host.
+// The certificate of the server must be valid for the ServerName of the files if set, or else for the
+// host, either a name or an IP address.
+// The files are reloaded when they change, so certificates can be rotated without restarting.
+// It fails if a file cannot be loaded or a certificate is expired.
+func NewClientConfig(files Files, host string) (*tls.Config, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("tls: the client certificate and its key must be set together")
+	}
+
+	name := files.ServerName
+	if name == "" {
+		name = host
+	}
+	r := &reloader{files: files, name: name}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: name,
+		// The certificate of the server is verified by verifyConnection with the current CA bundle,
+		// as the RootCAs of a configuration cannot be replaced once it is in use.
+		InsecureSkipVerify:   true,
+		VerifyConnection:     r.verifyConnection,
+		GetClientCertificate: r.getClientCertificate,
+	}, nil
+}
+
+// reloader holds the certificates loaded from the files and reloads them when the files change.
+type reloader struct {
+	files Files
+	name  string // name verified in the certificate of the server
+
+	mu       sync.RWMutex
+	roots    *x509.CertPool
+	cert     *tls.Certificate
+	modTimes map[string]time.Time
+}
+
+// load reads and validates all the files.
+func (r *reloader) load() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	var roots *x509.CertPool
+	if r.files.CAFile != "" {
+		roots, err = loadCAFile(r.files.CAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	cert := &tls.Certificate{}
+	if r.files.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tls: failed to load client certificate %s: %w", r.files.CertFile, err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("tls: failed to parse client certificate %s: %w", r.files.CertFile, err)
+		}
+		if err := checkValidity(leaf, r.files.CertFile); err != nil {
+			return err
+		}
+		pair.Leaf = leaf
+		cert = &pair
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roots = roots
+	r.cert = cert
+	r.modTimes = modTimes
+	return nil
+}
+
+// reloadIfChanged reloads the files if any of them changed. If the new files are not valid,
+// e.g. while they are being replaced, the previous certificates are kept.
+func (r *reloader) reloadIfChanged() {
+	modTimes, err := r.stat()
+	if err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	changed := false
+	for path, modTime := range modTimes {
+		if !r.modTimes[path].Equal(modTime) {
+			changed = true
+		}
+	}
+	r.mu.RUnlock()
+
+	if changed {
+		_ = r.load()
+	}
+}
+
+// stat returns the modification time of the files.
+func (r *reloader) stat() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{r.files.CAFile, r.files.CertFile, r.files.KeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *reloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *reloader) verifyConnection(cs tls.ConnectionState) error {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	roots := r.roots
+	r.mu.RUnlock()
+
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: the server did not present a certificate")
+	}
+	// The name is not taken from the connection state, as no server name is sent to IP addresses
+	// and the certificate would then be accepted for any name.
+	if r.name == "" {
+		return errors.New("tls: no server name to verify")
+	}
+	opts := x509.VerifyOptions{
+		DNSName:       r.name,
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// loadCAFile reads the CA bundle, all its certificates must be valid.
+func loadCAFile(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tls: failed to read CA file: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("tls: failed to parse CA file %s: %w", path, err)
+		}
+		if err := checkValidity(cert, path); err != nil {
+			return nil, err
+		}
+		roots.AddCert(cert)
+	}
+	if roots.Equal(x509.NewCertPool()) {
+		return nil, fmt.Errorf("tls: no certificates found in CA file %s", path)
+	}
+	return roots, nil
+}
+
+// checkValidity returns an error if the certificate is expired or not valid yet.
+func checkValidity(cert *x509.Certificate, path string) error {
+	now := time.Now()
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("tls: certificate %s (%s) expired on %s", path, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("tls: certificate %s (%s) is not valid until %s", path, cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, time.Now().Add(time.Hour))
+	serverCert := newCertificate(t, "ion-cannon", ca, time.Now().Add(time.Hour))
+	clientCert := newCertificate(t, "endor", ca, time.Now().Add(time.Hour))
+
+	files := Files{
+		CAFile:     ca.write(t, dir, "ca"),
+		CertFile:   clientCert.write(t, dir, "client"),
+		KeyFile:    filepath.Join(dir, "client-key.pem"),
+		ServerName: "ion-cannon",
+	}
+	server := newServer(t, ca, serverCert)
+	defer server.Close()
+
+	config, err := NewClientConfig(files, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := get(server.URL, config); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// The name of the server is verified
+	config, _ = NewClientConfig(Files{CAFile: files.CAFile, CertFile: files.CertFile, KeyFile: files.KeyFile, ServerName: "other"}, "127.0.0.1")
+	if err := get(server.URL, config); err == nil {
+		t.Errorf("Expected an error for a wrong server name")
+	}
+
+	// The server requires a client certificate
+	config, _ = NewClientConfig(Files{CAFile: files.CAFile, ServerName: "ion-cannon"}, "127.0.0.1")
+	if err := get(server.URL, config); err == nil {
+		t.Errorf("Expected an error without client certificate")
+	}
+}
+
+func TestNewClientConfig_Host(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, time.Now().Add(time.Hour))
+	caFile := ca.write(t, dir, "ca")
+
+	// The certificate of a server dialled by IP address is verified, even if no server name is sent
+	server := newServer(t, nil, newCertificate(t, "127.0.0.1", ca, time.Now().Add(time.Hour)))
+	defer server.Close()
+	config, err := NewClientConfig(Files{CAFile: caFile}, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := get(server.URL, config); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// A certificate of another name signed by the CA is rejected
+	other := newServer(t, nil, newCertificate(t, "ion-cannon", ca, time.Now().Add(time.Hour)))
+	defer other.Close()
+	if err := get(other.URL, config); err == nil {
+		t.Errorf("Expected an error for a certificate of another name")
+	}
+
+	// The server name overrides the host
+	config, _ = NewClientConfig(Files{CAFile: caFile, ServerName: "ion-cannon"}, "127.0.0.1")
+	if err := get(other.URL, config); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNewClientConfig_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, time.Now().Add(time.Hour))
+	other := newCertificate(t, "other-ca", nil, time.Now().Add(time.Hour))
+	serverCert := newCertificate(t, "ion-cannon", ca, time.Now().Add(time.Hour))
+	server := newServer(t, nil, serverCert)
+	defer server.Close()
+
+	files := Files{CAFile: other.write(t, dir, "ca"), ServerName: "ion-cannon"}
+	config, err := NewClientConfig(files, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := get(server.URL, config); err == nil {
+		t.Errorf("Expected an error for an unknown CA")
+	}
+
+	// The CA bundle is reloaded when it changes
+	ca.write(t, dir, "ca")
+	touch(t, files.CAFile, time.Now().Add(time.Minute))
+	if err := get(server.URL, config); err != nil {
+		t.Errorf("Unexpected error after the rotation: %v", err)
+	}
+
+	// An invalid file is ignored and the previous CA bundle is kept
+	if err := os.WriteFile(files.CAFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, files.CAFile, time.Now().Add(2*time.Minute))
+	if err := get(server.URL, config); err != nil {
+		t.Errorf("Unexpected error with an invalid CA file: %v", err)
+	}
+}
+
+func TestNewClientConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	ca := newCertificate(t, "ca", nil, time.Now().Add(time.Hour))
+	expired := newCertificate(t, "endor", ca, time.Now().Add(-time.Hour))
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files Files
+		err   string
+	}{
+		{"cert without key", Files{CertFile: ca.write(t, dir, "ca")}, "must be set together"},
+		{"missing CA", Files{CAFile: filepath.Join(dir, "missing.pem")}, "no such file"},
+		{"invalid CA", Files{CAFile: invalid}, "no certificates found"},
+		{"expired cert", Files{CertFile: expired.write(t, dir, "expired"), KeyFile: filepath.Join(dir, "expired-key.pem")}, "expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClientConfig(tt.files, "127.0.0.1")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+type certificate struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// newCertificate creates a certificate signed by the parent, or a self-signed CA if parent is nil.
+func newCertificate(t *testing.T, name string, parent *certificate, notAfter time.Time) *certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.DNSNames, template.IPAddresses = nil, []net.IP{ip}
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &certificate{cert: cert, der: der, key: key}
+}
+
+// write writes the certificate and its key to name.pem and name-key.pem, and returns the path of the certificate.
+func (c *certificate) write(t *testing.T, dir string, name string) string {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newServer starts a TLS server with the certificate, requiring client certificates signed by clientCA if set.
+func newServer(t *testing.T, clientCA *certificate, cert *certificate) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{cert.der}, PrivateKey: cert.key}},
+	}
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA.cert)
+		server.TLS.ClientCAs = pool
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	server.StartTLS()
+	return server
+}
+
+// get sends a request with a new connection, so every request performs a handshake.
+func get(url string, config *tls.Config) error {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+	res, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func touch(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
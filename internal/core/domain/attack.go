@@ -1,7 +1,26 @@
 package domain
 
+import "time"
+
 type Report struct {
-	Target     *Coordinate
-	Casualties int
-	Generation int
+	Target             *Coordinate
+	EnemyType          EnemyType
+	Casualties         int
+	Generation         int
+	CannonID           string        // identifier of the ion cannon fired
+	Candidates         int           // number of ion cannons that answered their status
+	Pipeline           []string      // protocols applied to find the target, in order
+	Timings            Timings       // time spent in every phase of the attack
+	WaitTime           time.Duration // time spent waiting for an available ion cannon
+	ExpectedCasualties float64       // estimated before firing with the casualty model
+	CasualtyDeviation  float64       // relative deviation of the casualties from the estimate
+	Anomalous          bool          // the deviation is above the threshold of the casualty model
+}
+
+// Timings holds the time spent in every phase of an attack.
+type Timings struct {
+	Targeting   time.Duration // applying the protocols to the scan
+	StatusCheck time.Duration // checking the status of the ion cannons
+	Fire        time.Duration // firing the ion cannon
+	Total       time.Duration
 }
@@ -0,0 +1,54 @@
+package domain
+
+import "math"
+
+// CasualtyModel estimates the casualties of a strike before firing the ion cannon.
+type CasualtyModel struct {
+	// KillRate is the fraction of enemies of each type destroyed by a 1st generation ion cannon.
+	KillRate map[EnemyType]float64
+	// GenerationFactor is the extra effectiveness of every generation above the first one.
+	GenerationFactor float64
+	// DeviationThreshold is the relative deviation between the actual and the expected casualties
+	// above which a strike is flagged.
+	DeviationThreshold float64
+}
+
+// DefaultCasualtyModel returns a model where every enemy at the target is destroyed.
+func DefaultCasualtyModel() CasualtyModel {
+	return CasualtyModel{
+		KillRate: map[EnemyType]float64{
+			Soldier: 1,
+			Mech:    1,
+		},
+		GenerationFactor:   0,
+		DeviationThreshold: 0.25,
+	}
+}
+
+// Estimate returns the expected casualties of firing an ion cannon of the given generation at the enemies.
+// The estimate never exceeds the number of enemies.
+func (m CasualtyModel) Estimate(enemies *Enemy, generation int) float64 {
+	if enemies == nil || enemies.Number <= 0 {
+		return 0
+	}
+
+	rate := m.KillRate[enemies.Type] * (1 + m.GenerationFactor*float64(generation-1))
+	rate = math.Max(0, math.Min(1, rate))
+	return float64(enemies.Number) * rate
+}
+
+// Deviation returns the relative deviation of the actual casualties from the expected ones.
+func (m CasualtyModel) Deviation(expected float64, actual int) float64 {
+	if expected == 0 {
+		if actual == 0 {
+			return 0
+		}
+		return 1
+	}
+	return (float64(actual) - expected) / expected
+}
+
+// IsAnomalous returns true if the deviation is above the threshold of the model.
+func (m CasualtyModel) IsAnomalous(deviation float64) bool {
+	return math.Abs(deviation) > m.DeviationThreshold
+}
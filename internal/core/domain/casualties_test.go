@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestCasualtyModel(t *testing.T) {
+	model := CasualtyModel{
+		KillRate:           map[EnemyType]float64{Soldier: 0.8, Mech: 0.5},
+		GenerationFactor:   0.5,
+		DeviationThreshold: 0.2,
+	}
+
+	testCases := []struct {
+		name       string
+		enemies    *Enemy
+		generation int
+		expected   float64
+	}{
+		{name: "soldiers 1st generation", enemies: &Enemy{Type: Soldier, Number: 10}, generation: 1, expected: 8},
+		{name: "mechs 2nd generation", enemies: &Enemy{Type: Mech, Number: 10}, generation: 2, expected: 7.5},
+		{name: "never more than the enemies", enemies: &Enemy{Type: Soldier, Number: 10}, generation: 3, expected: 10},
+		{name: "no enemies", enemies: &Enemy{Type: Soldier, Number: 0}, generation: 1, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		if got := model.Estimate(testCase.enemies, testCase.generation); got != testCase.expected {
+			t.Errorf("Unexpected estimate in test %s. Expected: %v, Got: %v", testCase.name, testCase.expected, got)
+		}
+	}
+
+	if deviation := model.Deviation(8, 6); deviation != -0.25 || !model.IsAnomalous(deviation) {
+		t.Errorf("Expected deviation -0.25 to be anomalous, got %v", deviation)
+	}
+	if deviation := model.Deviation(8, 9); deviation != 0.125 || model.IsAnomalous(deviation) {
+		t.Errorf("Expected deviation 0.125 not to be anomalous, got %v", deviation)
+	}
+}
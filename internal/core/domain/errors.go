@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of an attack. Adapters and services wrap them with the details of the failure,
+// so they must be checked with errors.Is.
+var (
+	// ErrNoValidTarget is returned when no scan is left after applying the protocols.
+	ErrNoValidTarget = errors.New("not valid target encountered")
+	// ErrNoCannonAvailable is returned when every ion cannon is unavailable, e.g. recharging.
+	ErrNoCannonAvailable = errors.New("failed to fire. No available ion cannons")
+	// ErrCannonUnreachable is returned when an ion cannon cannot be contacted.
+	ErrCannonUnreachable = errors.New("ion cannon unreachable")
+	// ErrCannonTimeout is returned when an ion cannon does not answer in time.
+	ErrCannonTimeout = errors.New("ion cannon timed out")
+	// ErrCannonStatusFailed is returned when an ion cannon answers its status with an error.
+	ErrCannonStatusFailed = errors.New("failed to check ion cannon status")
+	// ErrCannonFireFailed is returned when an ion cannon answers the fire command with an error.
+	ErrCannonFireFailed = errors.New("failed to fire ion cannon")
+	// ErrFireSent is matched by the errors of the fire commands that may have reached the ion cannon,
+	// so it may have fired despite the error and retrying the attack could fire twice.
+	ErrFireSent = errors.New("fire command sent")
+)
+
+// FireSent marks the error of a fire command as matching ErrFireSent.
+func FireSent(err error) error {
+	return &fireSentError{err: err}
+}
+
+type fireSentError struct {
+	err error
+}
+
+func (e *fireSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fireSentError) Unwrap() error {
+	return e.err
+}
+
+func (e *fireSentError) Is(target error) bool {
+	return target == ErrFireSent
+}
+
+// Errors of the ion cannons matched by the code of a CannonError.
+var (
+	// ErrCannonRecharging is returned when an ion cannon refuses to fire while it is recharging.
+	ErrCannonRecharging = errors.New("ion cannon recharging")
+	// ErrCannonBadTarget is returned when an ion cannon rejects the target of a fire command.
+	ErrCannonBadTarget = errors.New("ion cannon rejected the target")
+)
+
+// Codes of the errors answered by the ion cannons.
+const (
+	CannonErrorRecharging   = "recharging"
+	CannonErrorBadTarget    = "bad_target"
+	CannonErrorUnauthorized = "unauthorized"
+	CannonErrorRateLimited  = "rate_limited"
+	CannonErrorUnavailable  = "unavailable"
+	CannonErrorInternal     = "internal"
+	CannonErrorUnknown      = "unknown"
+)
+
+// CannonError is an error answered by an ion cannon. It wraps ErrCannonStatusFailed or ErrCannonFireFailed,
+// and matches ErrCannonRecharging or ErrCannonBadTarget depending on its code.
+type CannonError struct {
+	Op         error  // ErrCannonStatusFailed or ErrCannonFireFailed
+	StatusCode int    // HTTP status of the response, 0 for the cannons without HTTP API
+	Code       string // one of the CannonError* codes, or the code sent by the cannon
+	Message    string
+	Retryable  bool // whether the same request may succeed later
+}
+
+func (e *CannonError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: %s (%d): %s", e.Op, e.Code, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Op, e.Code, e.Message)
+}
+
+func (e *CannonError) Unwrap() error {
+	return e.Op
+}
+
+func (e *CannonError) Is(target error) bool {
+	switch target {
+	case ErrCannonRecharging:
+		return e.Code == CannonErrorRecharging
+	case ErrCannonBadTarget:
+		return e.Code == CannonErrorBadTarget
+	default:
+		return false
+	}
+}
+
+// Refused returns true if the ion cannon refused the command, so a fire command was not fired.
+func (e *CannonError) Refused() bool {
+	switch e.Code {
+	case CannonErrorRecharging, CannonErrorBadTarget, CannonErrorUnauthorized, CannonErrorRateLimited:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+// CannonDefinition describes an ion cannon discovered from a fleet source.
+type CannonDefinition struct {
+	ID       string
+	URL      string
+	Position *Coordinate
+	Tags     []string
+	TLS      *CannonTLS
+	Secret   *CannonSecret
+}
+
+// CannonTLS holds the files securing the connection with an ion cannon.
+type CannonTLS struct {
+	CAFile     string // CA bundle verifying the certificate of the cannon
+	CertFile   string // client certificate presented to the cannon, for mutual TLS
+	KeyFile    string // key of the client certificate
+	ServerName string // overrides the name verified in the certificate of the cannon
+}
+
+// CannonSecret locates the secret shared with an ion cannon to sign the fire commands,
+// read from a file or an environment variable.
+type CannonSecret struct {
+	File string
+	Env  string
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrJobNotFound is returned when an attack job does not exist.
+var ErrJobNotFound = errors.New("job not found")
+
+// JobStatus represents the status of an asynchronous attack job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is an attack executed asynchronously.
+type Job struct {
+	ID        string
+	Status    JobStatus
+	Radar     *Radar
+	Report    *Report
+	Error     string
+	ErrorCode string // machine-readable code of the error, kept with the message across restarts
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
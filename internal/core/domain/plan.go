@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// CannonCandidate holds the status reported by an ion cannon while choosing which one to fire.
+type CannonCandidate struct {
+	ID         string
+	Generation int
+	Available  bool
+	Err        error
+}
+
+// AttackPlan describes what an attack would do without firing any ion cannon.
+// Plans are stored until ExpiresAt and can be confirmed to fire the chosen cannon.
+type AttackPlan struct {
+	ID                 string
+	ExpiresAt          time.Time
+	Target             *Scan
+	Pipeline           []string // protocols applied to find the target, in order
+	Candidates         []*CannonCandidate
+	Cannon             *CannonCandidate // nil when there is no available ion cannon
+	ExpectedCasualties float64          // estimate of firing the chosen ion cannon
+	Timings            Timings
+}
+
+// Answered returns the number of ion cannons that answered their status, available or not.
+// Ion cannons that failed or whose status check was cancelled are not counted.
+func (p *AttackPlan) Answered() int {
+	answered := 0
+	for _, candidate := range p.Candidates {
+		if candidate.Err == nil {
+			answered++
+		}
+	}
+	return answered
+}
@@ -42,6 +42,7 @@ func ParseStringToProtocolType(str string) (ProtocolType, error) {
 // Protocol is the interface that defines the methods for applying a protocol to a list of scans.
 type Protocol interface {
 	apply(scans []*Scan) []*Scan
+	String() string
 }
 
 // ProtocolDistanceLimit is a protocol that filters scans based on a maximum distance limit.
@@ -49,6 +50,11 @@ type ProtocolDistanceLimit struct {
 	MaxDistance float64
 }
 
+// String returns the name of the protocol including its distance limit.
+func (p ProtocolDistanceLimit) String() string {
+	return fmt.Sprintf("distance-limit:%g", p.MaxDistance)
+}
+
 // apply applies the ProtocolDistanceLimit to the provided scans and filters out scans beyond the maximum distance limit.
 func (p ProtocolDistanceLimit) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -70,6 +76,11 @@ func (p ProtocolDistanceLimit) apply(scans []*Scan) []*Scan {
 // ProtocolClosestEnemies is a protocol that sorts scans based on the distance to the enemies in ascending order.
 type ProtocolClosestEnemies struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolClosestEnemies) String() string {
+	return string(ClosestEnemies)
+}
+
 // apply applies the ProtocolClosestEnemies to the provided scans and sorts them based on the distance to enemies in ascending order.
 func (p ProtocolClosestEnemies) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -88,6 +99,11 @@ func (p ProtocolClosestEnemies) apply(scans []*Scan) []*Scan {
 // ProtocolFurthestEnemies is a protocol that sorts scans based on the distance to the enemies in descending order.
 type ProtocolFurthestEnemies struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolFurthestEnemies) String() string {
+	return string(FurthestEnemies)
+}
+
 // apply applies the ProtocolFurthestEnemies to the provided scans and sorts them based on the distance to enemies in descending order.
 func (p ProtocolFurthestEnemies) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -106,6 +122,11 @@ func (p ProtocolFurthestEnemies) apply(scans []*Scan) []*Scan {
 // ProtocolAssistAllies is a protocol that filters scans to only include scans with allies present.
 type ProtocolAssistAllies struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolAssistAllies) String() string {
+	return string(AssistAllies)
+}
+
 // apply applies the ProtocolAssistAllies to the provided scans and filters out scans without any allies present.
 func (p ProtocolAssistAllies) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -126,6 +147,11 @@ func (p ProtocolAssistAllies) apply(scans []*Scan) []*Scan {
 // ProtocolAvoidCrossfire is a protocol that filters scans to only include scans without any allies present.
 type ProtocolAvoidCrossfire struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolAvoidCrossfire) String() string {
+	return string(AvoidCrossfire)
+}
+
 // apply applies the ProtocolAvoidCrossfire to the provided scans and filters out scans with any allies present.
 func (p ProtocolAvoidCrossfire) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -146,6 +172,11 @@ func (p ProtocolAvoidCrossfire) apply(scans []*Scan) []*Scan {
 // ProtocolPrioritizeMech is a protocol that prioritizes scans with mech enemies and includes any other enemy type if no mech enemies are found.
 type ProtocolPrioritizeMech struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolPrioritizeMech) String() string {
+	return string(PrioritizeMech)
+}
+
 // apply applies the ProtocolPrioritizeMech to the provided scans and filters out scans that do not match the prioritization criteria.
 func (p ProtocolPrioritizeMech) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -175,6 +206,11 @@ func (p ProtocolPrioritizeMech) apply(scans []*Scan) []*Scan {
 // ProtocolAvoidMech is a protocol that filters out scans with mech enemies.
 type ProtocolAvoidMech struct{}
 
+// String returns the name of the protocol.
+func (p ProtocolAvoidMech) String() string {
+	return string(AvoidMech)
+}
+
 // apply applies the ProtocolAvoidMech to the provided scans and filters out scans with mech enemies.
 func (p ProtocolAvoidMech) apply(scans []*Scan) []*Scan {
 	if len(scans) == 0 {
@@ -222,6 +258,15 @@ func GetProtocols(protocolTypes []ProtocolType) []Protocol {
 	return protocols
 }
 
+// ProtocolNames returns the names of the protocols in the order they are applied.
+func ProtocolNames(protocols []Protocol) []string {
+	names := make([]string, len(protocols))
+	for i, p := range protocols {
+		names[i] = p.String()
+	}
+	return names
+}
+
 // ApplyProtocols applies the specified protocols to the provided scans and returns the resulting scans.
 func ApplyProtocols(scans []*Scan, protocol ...Protocol) []*Scan {
 	result := make([]*Scan, len(scans))
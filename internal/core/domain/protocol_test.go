@@ -187,3 +187,11 @@ func TestProtocolAvoidMech_apply(t *testing.T) {
 		})
 	}
 }
+
+func TestProtocolNames(t *testing.T) {
+	names := ProtocolNames(GetProtocols([]ProtocolType{ClosestEnemies, AvoidMech}))
+	expected := []string{"distance-limit:100", "closest-enemies", "avoid-mech"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", expected, names)
+	}
+}
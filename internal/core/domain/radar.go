@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type Scan struct {
 	Coordinates *Coordinate
 	Enemies     *Enemy
@@ -9,4 +11,5 @@ type Scan struct {
 type Radar struct {
 	Protocols []ProtocolType
 	Scan      []*Scan
+	MaxWait   time.Duration // maximum time to wait for an available ion cannon, zero fails immediately
 }
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// DefaultDiscoveryInterval is the default interval between two reads of the fleet source.
+const DefaultDiscoveryInterval = 5 * time.Second
+
+// FleetDiscovery keeps the fleet in sync with the ion cannons defined in a source.
+// The source is polled, so changes are applied without restarting the service
+// whatever the way the source is updated (e.g. files replaced atomically or mounted volumes).
+type FleetDiscovery struct {
+	fleet    *Fleet
+	source   adapters.FleetSource
+	name     string
+	interval time.Duration
+	log      *zap.SugaredLogger
+
+	last    []*domain.CannonDefinition // definitions of the last successful read
+	lastErr string                     // error of the last read, logged only once
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewFleetDiscovery creates a new instance of the FleetDiscovery. The name identifies the cannons
+// managed by the source in the fleet.
+func NewFleetDiscovery(fleet *Fleet, source adapters.FleetSource, name string, interval time.Duration, log *zap.SugaredLogger) *FleetDiscovery {
+	if interval <= 0 {
+		interval = DefaultDiscoveryInterval
+	}
+	if log == nil {
+		log = zap.NewNop().Sugar()
+	}
+	return &FleetDiscovery{
+		fleet:    fleet,
+		source:   source,
+		name:     name,
+		interval: interval,
+		log:      log,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start synchronises the fleet with the source and starts watching it for changes.
+// It fails if the source cannot be read or applied, later failures are logged and the fleet is kept as is.
+func (d *FleetDiscovery) Start() error {
+	definitions, err := d.source.Load()
+	if err != nil {
+		return fmt.Errorf("failed to discover ion cannons from %s: %w", d.name, err)
+	}
+	if err := d.apply(definitions); err != nil {
+		return fmt.Errorf("failed to discover ion cannons from %s: %w", d.name, err)
+	}
+
+	d.wg.Add(1)
+	go d.watch()
+	return nil
+}
+
+// Stop stops watching the source.
+func (d *FleetDiscovery) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+}
+
+// refresh reads the source and applies its changes to the fleet.
+func (d *FleetDiscovery) refresh() {
+	definitions, err := d.source.Load()
+	if err == nil && reflect.DeepEqual(definitions, d.last) {
+		d.lastErr = ""
+		return
+	}
+	if err == nil {
+		err = d.apply(definitions)
+	}
+	if err != nil {
+		if err.Error() != d.lastErr {
+			d.log.Errorw("failed to discover ion cannons, keeping the current fleet", "source", d.name, "error", err)
+		}
+		d.lastErr = err.Error()
+		return
+	}
+	d.lastErr = ""
+}
+
+func (d *FleetDiscovery) watch() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.refresh()
+		}
+	}
+}
+
+// apply synchronises the fleet with the definitions and logs the changes.
+func (d *FleetDiscovery) apply(definitions []*domain.CannonDefinition) error {
+	diff, err := d.fleet.Sync(d.name, definitions)
+	if err != nil {
+		return err
+	}
+	d.last = definitions
+
+	if !diff.Empty() {
+		d.log.Infow("ion cannon fleet updated", "source", d.name, "added", diff.Added, "removed", diff.Removed, "updated", diff.Updated)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFleetDiscovery(t *testing.T) {
+	created := 0
+	fleet := NewFleet(func(d *domain.CannonDefinition) (adapters.IonCannon, error) {
+		created++
+		return &mocks.IonCannonClientMock{CannonID: d.ID}, nil
+	})
+	assert.NoError(t, fleet.Register("env-1", "http://env-1:3000", &mocks.IonCannonClientMock{CannonID: "env-1"}))
+
+	source := &mocks.FleetSourceMock{Definitions: []*domain.CannonDefinition{
+		{ID: "cannon-1", URL: "http://cannon-1:3000"},
+		{ID: "cannon-2", URL: "http://cannon-2:3000", Tags: []string{"north"}},
+	}}
+	discovery := NewFleetDiscovery(fleet, source, "fleet.yaml", time.Hour, nil)
+	assert.NoError(t, discovery.Start())
+	defer discovery.Stop()
+
+	assert.Equal(t, []string{"env-1", "cannon-1", "cannon-2"}, fleetIDs(fleet))
+	assert.Equal(t, 2, created)
+
+	// Cannons keep their state and client unless their URL changes
+	_, err := fleet.SetEnabled("cannon-2", false)
+	assert.NoError(t, err)
+	source.Set([]*domain.CannonDefinition{
+		{ID: "cannon-2", URL: "http://cannon-2:3000", Position: domain.NewCoordinates(1, 2)},
+		{ID: "cannon-3", URL: "http://cannon-3:3000"},
+	}, nil)
+	discovery.refresh()
+
+	assert.Equal(t, []string{"env-1", "cannon-2", "cannon-3"}, fleetIDs(fleet))
+	assert.Equal(t, 3, created)
+	cannon, err := fleet.Get("cannon-2")
+	assert.NoError(t, err)
+	assert.False(t, cannon.Enabled)
+	assert.Equal(t, domain.NewCoordinates(1, 2), cannon.Position)
+	assert.Equal(t, "fleet.yaml", cannon.Source)
+
+	// A new client is created when the TLS configuration of a cannon changes
+	tls := &domain.CannonTLS{CAFile: "ca.pem", ServerName: "cannon-3"}
+	source.Set([]*domain.CannonDefinition{
+		{ID: "cannon-2", URL: "http://cannon-2:3000", Position: domain.NewCoordinates(1, 2)},
+		{ID: "cannon-3", URL: "http://cannon-3:3000", TLS: tls},
+	}, nil)
+	discovery.refresh()
+	assert.Equal(t, 4, created)
+	cannon, err = fleet.Get("cannon-3")
+	assert.NoError(t, err)
+	assert.Equal(t, tls, cannon.TLS)
+
+	// Invalid sources are ignored and the fleet is kept as is
+	source.Set(nil, errors.New("unexpected EOF"))
+	discovery.refresh()
+	source.Set([]*domain.CannonDefinition{{ID: "env-1", URL: "http://env-1:3000"}}, nil)
+	discovery.refresh()
+	source.Set([]*domain.CannonDefinition{{ID: "cannon-4", URL: "cannon-4"}}, nil)
+	discovery.refresh()
+	assert.Equal(t, []string{"env-1", "cannon-2", "cannon-3"}, fleetIDs(fleet))
+
+	source.Set(nil, nil)
+	discovery.refresh()
+	assert.Equal(t, []string{"env-1"}, fleetIDs(fleet))
+}
+
+func TestFleetDiscovery_StartError(t *testing.T) {
+	source := &mocks.FleetSourceMock{Err: errors.New("no such file or directory")}
+	discovery := NewFleetDiscovery(NewFleet(nil), source, "fleet.yaml", time.Hour, nil)
+
+	assert.Error(t, discovery.Start())
+}
+
+func fleetIDs(fleet *Fleet) []string {
+	ids := []string{}
+	for _, c := range fleet.List() {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
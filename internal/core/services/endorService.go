@@ -1,141 +1,587 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 	"go.uber.org/zap"
 )
 
-// MAX_GORUTINES defines the maximum number of goroutines to execute concurrently.
-var MAX_GORUTINES = 1000 // TODO: Have a configurable value
-var log *zap.SugaredLogger
+const (
+	// initialWaitBackoff is the first delay between status checks while waiting for an available ion cannon.
+	initialWaitBackoff = 50 * time.Millisecond
+	// maxWaitBackoff is the maximum delay between status checks while waiting for an available ion cannon.
+	maxWaitBackoff = time.Second
+)
 
-// semaphore is used to limit concurrency to MAX_GORUTINES.
-var semaphore chan struct{}
+// DefaultStatusTimeout is the default maximum time to wait for the status of an ion cannon.
+const DefaultStatusTimeout = 3 * time.Second
 
-// Simple semaphore to control the total numer of gorutines executed.
-// For production we should have a hard limit on the number of gorutines we want in a way
-// developers should not need to think much about it to  avoid loosing control over how
-// many gorutines are executed in parralel.
-// We can create a custom ParallelIonCannon and have that be in control. But in the real
-// world scenario, we will query different endpoints with different parameters.
+// ErrStatusCheckCancelled is set on the ion cannons whose status was not awaited,
+// as a better ion cannon had already answered.
+var ErrStatusCheckCancelled = errors.New("status check cancelled, a better ion cannon was already found")
 
 // EndorService represents the Endor service.
 type EndorService struct {
-	ionCannons []adapters.IonCannon
+	fleet          *Fleet
+	log            *zap.SugaredLogger
+	maxConcurrency int
+	pool           *WorkerPool
+	leases         *leaseManager
+	planTTL        time.Duration
+	plans          *planStore
+	casualtyModel  domain.CasualtyModel
+	statusTimeout  time.Duration
+	earlySelection bool
+	generations    sync.Map // last known generation of every ion cannon by ID, used by the early selection
+
+	minReadyCannons int
+	shuttingDown    atomic.Bool
+}
+
+// Option configures the EndorService.
+type Option func(*EndorService)
+
+// WithLogger sets the logger used by the service.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(s *EndorService) {
+		s.log = logger
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of goroutines the service executes concurrently.
+func WithMaxConcurrency(n int) Option {
+	return func(s *EndorService) {
+		s.maxConcurrency = n
+	}
+}
+
+// WithPlanTTL sets the time a stored attack plan can be confirmed before it expires.
+func WithPlanTTL(ttl time.Duration) Option {
+	return func(s *EndorService) {
+		s.planTTL = ttl
+	}
+}
+
+// WithCasualtyModel sets the model used to estimate the casualties of a strike before firing.
+func WithCasualtyModel(model domain.CasualtyModel) Option {
+	return func(s *EndorService) {
+		s.casualtyModel = model
+	}
+}
+
+// WithFleet sets the registry of ion cannons the service fires.
+func WithFleet(fleet *Fleet) Option {
+	return func(s *EndorService) {
+		s.fleet = fleet
+	}
+}
+
+// WithStatusTimeout sets the maximum time to wait for the status of every ion cannon.
+// Ion cannons not answering in time are not considered by the attack.
+func WithStatusTimeout(timeout time.Duration) Option {
+	return func(s *EndorService) {
+		s.statusTimeout = timeout
+	}
+}
+
+// WithEarlySelection makes attacks stop waiting for the status of the ion cannons as soon as an
+// available one is known to be the best choice, cancelling the status checks still running.
+// An ion cannon is the best choice when every ion cannon still checking is known, from previous
+// attacks, to be of the same or a higher generation.
+func WithEarlySelection(enabled bool) Option {
+	return func(s *EndorService) {
+		s.earlySelection = enabled
+	}
+}
+
+// WithMinReadyCannons sets the minimum number of reachable ion cannons for the service to be ready.
+func WithMinReadyCannons(n int) Option {
+	return func(s *EndorService) {
+		s.minReadyCannons = n
+	}
 }
 
 // NewEndorService creates a new instance of the EndorService.
-func NewEndorService(logger *zap.SugaredLogger, ionCanons []adapters.IonCannon) *EndorService {
-	log = logger
-	semaphore = make(chan struct{}, MAX_GORUTINES)
+// The ion cannons are registered in the fleet of the service, which is empty unless set with WithFleet.
+func NewEndorService(ionCanons []adapters.IonCannon, opts ...Option) *EndorService {
+	s := &EndorService{
+		fleet:          NewFleet(nil),
+		log:            zap.NewNop().Sugar(),
+		maxConcurrency: DefaultMaxConcurrency,
+		planTTL:        DefaultPlanTTL,
+		casualtyModel:  domain.DefaultCasualtyModel(),
+		statusTimeout:  DefaultStatusTimeout,
 
-	return &EndorService{
-		ionCannons: ionCanons,
+		minReadyCannons: DefaultMinReadyCannons,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	// The generation of a retired cannon is forgotten, the cannon registered with the same ID may be another one
+	s.fleet.OnRetired(func(id string) {
+		s.generations.Delete(id)
+	})
+	for _, c := range ionCanons {
+		if err := s.fleet.Register(c.ID(), "", c); err != nil {
+			s.log.Errorf("Failed to register ion cannon: %v\n", err)
+		}
+	}
+	s.pool = NewWorkerPool(s.maxConcurrency)
+	s.leases = newLeaseManager()
+	s.plans = newPlanStore(s.planTTL)
+
+	// The client of a retired cannon is closed once the attacks firing it are done
+	s.fleet.setWaitIdle(s.leases.waitReleased)
+
+	return s
+}
+
+// Fleet returns the registry of ion cannons of the service.
+func (m *EndorService) Fleet() *Fleet {
+	return m.fleet
+}
+
+// StatusTimeout returns the maximum time to wait for the status of an ion cannon.
+func (m *EndorService) StatusTimeout() time.Duration {
+	return m.statusTimeout
+}
+
+// PoolStats returns the usage of the worker pool of the service.
+func (m *EndorService) PoolStats() PoolStats {
+	return m.pool.Stats()
 }
 
 // Attack performs the attack action on the specified target.
-func (m *EndorService) Attack(attack *domain.Radar) (*domain.Report, error) {
-	// We only have one action to make, so making a more complex structure does not make sense for now.
-	listOfProtocols := domain.GetProtocols(attack.Protocols)
-	targets := domain.ApplyProtocols(attack.Scan, listOfProtocols...)
+// The chosen ion cannon is reserved until it has fired, so concurrent attacks pick a different one
+// or queue for one. If no ion cannon is available and the radar allows it, the attack waits up to
+// attack.MaxWait for the first ion cannon to become available.
+func (m *EndorService) Attack(ctx context.Context, attack *domain.Radar) (*domain.Report, error) {
+	start := time.Now()
+	p, err := m.plan(ctx, attack, m.earlySelection)
+	if err != nil {
+		return nil, err
+	}
 
-	var finalTarget *domain.Coordinate
-	var numberOfEmemies int
-	if len(targets) > 0 {
-		finalTarget = targets[0].Coordinates
-		numberOfEmemies = targets[0].Enemies.Number
-	} else {
-		return nil, fmt.Errorf("not valid target encountered")
+	waitTime, err := m.reserveIonCannon(ctx, p, attack.MaxWait)
+	if err != nil {
+		return nil, err
 	}
+	defer m.leases.release(p.ionCannon)
 
-	cas, gen, err := fire(finalTarget.X, finalTarget.Y, numberOfEmemies, m.ionCannons)
+	report, err := m.strike(p.plan, p.ionCannon)
 	if err != nil {
 		return nil, err
 	}
+	report.WaitTime = waitTime
+	report.Timings.Total = time.Since(start)
+	return report, nil
+}
 
+// Plan runs the full attack pipeline without firing any ion cannon.
+// It returns the chosen target, the status of every ion cannon and the one that would fire.
+// If no ion cannons are available, the plan is returned without a chosen cannon.
+// The plan is stored and can be fired with Confirm until it expires.
+// The status of every ion cannon is awaited, even with early selection.
+func (m *EndorService) Plan(ctx context.Context, attack *domain.Radar) (*domain.AttackPlan, error) {
+	p, err := m.plan(ctx, attack, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.plans.add(p); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+// Confirm fires the stored attack plan with the given ID.
+// The availability of the planned ion cannon is verified again before firing, and plans whose ion cannon
+// was removed from the fleet, disabled or replaced since are rejected with ErrCannonRetired.
+// Expired or already executed plans are rejected, a plan is never fired twice.
+func (m *EndorService) Confirm(ctx context.Context, planID string) (*domain.Report, error) {
+	start := time.Now()
+	p, err := m.plans.acquire(planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ionCannon == nil {
+		m.plans.release(p)
+		return nil, domain.ErrNoCannonAvailable
+	}
+
+	if reserved, _ := m.leases.acquire([]adapters.IonCannon{p.ionCannon}); reserved < 0 {
+		m.plans.release(p)
+		return nil, fmt.Errorf("%w: planned ion cannon %s is being fired by another attack", domain.ErrNoCannonAvailable, p.plan.Cannon.ID)
+	}
+	defer m.leases.release(p.ionCannon)
+
+	// The planned cannon must still be enabled in the fleet with the same client, checked once reserved
+	// so its client is not closed meanwhile
+	if !m.fleet.isActive(p.ionCannon) {
+		m.plans.release(p)
+		return nil, fmt.Errorf("%w: planned ion cannon %s", ErrCannonRetired, p.plan.Cannon.ID)
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, m.statusTimeout)
+	defer cancel()
+	status, err := p.ionCannon.CheckStatus(statusCtx)
+	if err != nil {
+		m.plans.release(p)
+		m.log.Errorf("Failed to check status: %v\n", err)
+		return nil, cannonError(err, domain.ErrCannonStatusFailed)
+	}
+	if !status.Available {
+		m.plans.release(p)
+		return nil, fmt.Errorf("%w: planned ion cannon %s is no longer available", domain.ErrNoCannonAvailable, p.plan.Cannon.ID)
+	}
+
+	// From this point the plan is considered executed even if the fire command fails,
+	// as we cannot know if the ion cannon fired or not.
+	defer m.plans.done(p)
+
+	report, err := m.strike(p.plan, p.ionCannon)
+	if err != nil {
+		return nil, err
+	}
+	report.Timings.Total = time.Since(start)
+	return report, nil
+}
+
+// strike fires the ion cannon chosen by the plan and compares the casualties with the estimate.
+// Strikes deviating significantly from the estimate are flagged in the report.
+func (m *EndorService) strike(plan *domain.AttackPlan, ionCannon adapters.IonCannon) (*domain.Report, error) {
+	expected := m.casualtyModel.Estimate(plan.Target.Enemies, plan.Cannon.Generation)
+
+	finalTarget := plan.Target.Coordinates
+	fireStart := time.Now()
+	cas, gen, err := m.fire(finalTarget.X, finalTarget.Y, plan.Target.Enemies.Number, ionCannon)
+	if err != nil {
+		return nil, err
+	}
+
+	timings := plan.Timings
+	timings.Fire = time.Since(fireStart)
+
+	deviation := m.casualtyModel.Deviation(expected, cas)
 	report := &domain.Report{
-		Target:     finalTarget,
-		Casualties: cas,
-		Generation: gen,
+		Target:             finalTarget,
+		EnemyType:          plan.Target.Enemies.Type,
+		Casualties:         cas,
+		Generation:         gen,
+		CannonID:           plan.Cannon.ID,
+		Candidates:         plan.Answered(),
+		Pipeline:           plan.Pipeline,
+		Timings:            timings,
+		ExpectedCasualties: expected,
+		CasualtyDeviation:  deviation,
+		Anomalous:          m.casualtyModel.IsAnomalous(deviation),
+	}
+	if report.Anomalous {
+		m.log.Warnf("Strike at (%d, %d) deviates from the estimate: expected %.2f casualties, got %d\n",
+			finalTarget.X, finalTarget.Y, expected, cas)
 	}
 	return report, nil
 }
 
-// fire fires the ion cannons at the specified target coordinates.
-// It checks the status of all ion cannons concurrently and selects the one with the lowest generation to fire.
-// If no ion cannons are available, it returns an error.
-func fire(x, y, enemies int, ionCannons []adapters.IonCannon) (casualties int, generation int, err error) {
-	type ChannelResponse struct {
-		Available       bool
-		Generation      int
-		IonCannonClient adapters.IonCannon
-		Err             error
+// plannedAttack holds an attack plan with the ion cannons it considered.
+type plannedAttack struct {
+	plan       *domain.AttackPlan
+	ionCannons []adapters.IonCannon // snapshot of the fleet, in the same order as plan.Candidates
+	ionCannon  adapters.IonCannon   // ion cannon chosen to fire, nil if none is available
+}
+
+// plan builds the attack plan with a snapshot of the fleet and chooses the ion cannon to fire, if any.
+func (m *EndorService) plan(ctx context.Context, attack *domain.Radar, early bool) (*plannedAttack, error) {
+	// We only have one action to make, so making a more complex structure does not make sense for now.
+	start := time.Now()
+	listOfProtocols := domain.GetProtocols(attack.Protocols)
+	targets := domain.ApplyProtocols(attack.Scan, listOfProtocols...)
+	if len(targets) == 0 {
+		return nil, domain.ErrNoValidTarget
 	}
+	targeting := time.Since(start)
 
-	responses := make(chan ChannelResponse, len(ionCannons))
-	var wgCanon sync.WaitGroup
+	start = time.Now()
+	ionCannons := m.fleet.IonCannons()
+	candidates := m.checkStatus(ctx, ionCannons, early)
+	selected := selectIonCannon(candidates)
 
-	// Query status of all ion cannons concurrently
-	for _, c := range ionCannons {
-		semaphore <- struct{}{}
-		wgCanon.Add(1)
-		go func(c adapters.IonCannon) {
-			defer func() {
-				defer wgCanon.Done()
-				<-semaphore
-			}()
-			res, err := c.CheckStatus()
-			if err != nil {
-				log.Errorf("Failed to check status: %v\n", err)
-				responses <- ChannelResponse{Available: false, Generation: 0, Err: err}
-				return
+	plan := &domain.AttackPlan{
+		Target:     targets[0],
+		Pipeline:   domain.ProtocolNames(listOfProtocols),
+		Candidates: candidates,
+		Timings: domain.Timings{
+			Targeting:   targeting,
+			StatusCheck: time.Since(start),
+		},
+	}
+	p := &plannedAttack{plan: plan, ionCannons: ionCannons}
+	if selected < 0 {
+		return p, nil
+	}
+
+	plan.Cannon = candidates[selected]
+	plan.ExpectedCasualties = m.casualtyModel.Estimate(plan.Target.Enemies, plan.Cannon.Generation)
+	p.ionCannon = ionCannons[selected]
+	return p, nil
+}
+
+// reserveIonCannon reserves the best available ion cannon that is not being fired by another attack.
+// If every available ion cannon is reserved, it queues until one of them is released.
+// If no ion cannon is available and maxWait is set, it polls the status of the ion cannons with an
+// exponential backoff until one of them is available or maxWait is reached.
+// The plan is updated with the last status received and the reserved ion cannon.
+func (m *EndorService) reserveIonCannon(
+	ctx context.Context,
+	p *plannedAttack,
+	maxWait time.Duration,
+) (waitTime time.Duration, err error) {
+	plan := p.plan
+	if maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxWait)
+		defer cancel()
+	}
+
+	start := time.Now()
+	backoff := initialWaitBackoff
+	for {
+		ranking := rankIonCannons(plan.Candidates)
+		ionCannons := make([]adapters.IonCannon, len(ranking))
+		for i, idx := range ranking {
+			ionCannons[i] = p.ionCannons[idx]
+		}
+
+		reserved, released := m.leases.acquire(ionCannons)
+		if reserved >= 0 && !m.fleet.isActive(ionCannons[reserved]) {
+			// The cannon was retired after the snapshot of the fleet, so its client may be closed.
+			// Cannons still in the fleet once reserved are not closed until they are released.
+			m.leases.release(ionCannons[reserved])
+			plan.Candidates[ranking[reserved]].Err = ErrCannonRetired
+			continue
+		}
+		if reserved >= 0 {
+			plan.Cannon = plan.Candidates[ranking[reserved]]
+			p.ionCannon = ionCannons[reserved]
+			if waitTime > 0 {
+				waitTime = time.Since(start)
+			}
+			return waitTime, nil
+		}
+
+		// Only one of the channels is set, the other one blocks forever.
+		var queue <-chan struct{}
+		var poll <-chan time.Time
+		switch {
+		case len(ranking) > 0:
+			// Every available ion cannon is being fired by another attack, queue for one.
+			queue = released
+		case maxWait > 0:
+			poll = time.After(backoff)
+			backoff *= 2
+			if backoff > maxWaitBackoff {
+				backoff = maxWaitBackoff
 			}
+		default:
+			return 0, noCannonError(plan.Candidates)
+		}
 
-			responses <- ChannelResponse{
-				Available:       res.Available,
-				Generation:      res.Generation,
-				IonCannonClient: c,
-				Err:             nil,
+		select {
+		case <-ctx.Done():
+			if maxWait > 0 && ctx.Err() == context.DeadlineExceeded {
+				return 0, fmt.Errorf("%w after waiting %s", domain.ErrNoCannonAvailable, maxWait)
 			}
-		}(c)
+			return 0, ctx.Err()
+		case <-queue:
+		case <-poll:
+		}
+
+		waitTime = time.Since(start)
+		plan.Candidates = m.checkStatus(ctx, p.ionCannons, m.earlySelection)
+		plan.Cannon = nil
+		p.ionCannon = nil
 	}
+}
 
-	wgCanon.Wait()
-	close(responses)
+// checkStatus checks the status of all ion cannons concurrently, every call bounded by the status timeout.
+// With early selection it returns as soon as an available ion cannon is known to be the best choice,
+// the checks still running are cancelled and their candidates set with ErrStatusCheckCancelled.
+// The candidates are returned in the same order as the ion cannons.
+func (m *EndorService) checkStatus(ctx context.Context, ionCannons []adapters.IonCannon, early bool) []*domain.CannonCandidate {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i      int
+		status *domain.IonCannon
+		err    error
+	}
+	// Buffered, so the checks cancelled by an early selection never block.
+	results := make(chan result, len(ionCannons))
+
+	// Query status of all ion cannons concurrently
+	candidates := make([]*domain.CannonCandidate, len(ionCannons))
+	for i, c := range ionCannons {
+		i, c := i, c
+		candidates[i] = &domain.CannonCandidate{ID: c.ID()}
+		waited := m.pool.Go(func() {
+			callCtx, cancelCall := context.WithTimeout(ctx, m.statusTimeout)
+			defer cancelCall()
 
-	// Get the lowest generation of ion cannon available to fire
-	lowestGeneration := math.MaxInt
-	var lowestGenerationIonCannon adapters.IonCannon
+			status, err := c.CheckStatus(callCtx)
+			results <- result{i: i, status: status, err: err}
+		})
+		if waited {
+			m.log.Warnf("Worker pool saturated: %+v\n", m.pool.Stats())
+		}
+	}
 
-	for response := range responses {
-		if response.Err != nil {
+	answered := make([]bool, len(ionCannons))
+	for pending := len(ionCannons); pending > 0; pending-- {
+		res := <-results
+		answered[res.i] = true
+		candidate := candidates[res.i]
+		if res.err != nil {
+			m.log.Errorf("Failed to check status: %v\n", res.err)
+			candidate.Err = cannonError(res.err, domain.ErrCannonStatusFailed)
 			continue
 		}
 
-		if response.Available {
-			if response.Generation < lowestGeneration {
-				lowestGeneration = response.Generation
-				lowestGenerationIonCannon = response.IonCannonClient
+		candidate.Available = res.status.Available
+		candidate.Generation = res.status.Generation
+		m.generations.Store(ionCannons[res.i].ID(), res.status.Generation)
+
+		if early && pending > 1 && m.isBestChoice(ionCannons, candidates, answered) {
+			for i, ok := range answered {
+				if !ok {
+					candidates[i].Err = ErrStatusCheckCancelled
+				}
 			}
+			break
 		}
 	}
+	return candidates
+}
 
-	if lowestGenerationIonCannon == nil {
-		return 0, 0, fmt.Errorf("failed to fire. No available ion cannons")
+// isBestChoice returns true if the best ion cannon that answered cannot be beaten by the ion cannons
+// still checking, as their last known generation is not lower. Ion cannons reserved by other attacks
+// are ignored, and ion cannons never seen before could be of any generation.
+func (m *EndorService) isBestChoice(ionCannons []adapters.IonCannon, candidates []*domain.CannonCandidate, answered []bool) bool {
+	best := -1
+	for i, candidate := range candidates {
+		if !answered[i] || candidate.Err != nil || !candidate.Available || m.leases.isLeased(ionCannons[i]) {
+			continue
+		}
+		if best < 0 || candidate.Generation < candidates[best].Generation {
+			best = i
+		}
+	}
+	if best < 0 {
+		return false
 	}
 
-	casualties, generation, err = lowestGenerationIonCannon.FireCommand(x, y, enemies)
+	for i, c := range ionCannons {
+		if answered[i] || m.leases.isLeased(c) {
+			continue
+		}
+		generation, ok := m.generations.Load(c.ID())
+		if !ok || generation.(int) < candidates[best].Generation {
+			return false
+		}
+	}
+	return true
+}
+
+// selectIonCannon returns the index of the available ion cannon with the lowest generation.
+// If no ion cannons are available, it returns -1.
+func selectIonCannon(candidates []*domain.CannonCandidate) int {
+	ranking := rankIonCannons(candidates)
+	if len(ranking) == 0 {
+		return -1
+	}
+	return ranking[0]
+}
+
+// rankIonCannons returns the indexes of the available ion cannons, the lowest generation first.
+func rankIonCannons(candidates []*domain.CannonCandidate) []int {
+	ranking := []int{}
+	for i, candidate := range candidates {
+		if candidate.Err != nil {
+			continue
+		}
+
+		if candidate.Available {
+			ranking = append(ranking, i)
+		}
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return candidates[ranking[i]].Generation < candidates[ranking[j]].Generation
+	})
+	return ranking
+}
+
+// fire fires the ion cannon at the specified target coordinates.
+func (m *EndorService) fire(x, y, enemies int, ionCannon adapters.IonCannon) (casualties int, generation int, err error) {
+	if ionCannon == nil {
+		return 0, 0, domain.ErrNoCannonAvailable
+	}
+
+	// The fire command is never cancelled by the caller, as we could not know if the ion cannon fired.
+	casualties, generation, err = ionCannon.FireCommand(context.Background(), x, y, enemies)
 	if err != nil {
-		log.Errorf("Failed to fire command: %v\n", err)
+		m.log.Errorf("Failed to fire command: %v\n", err)
+		err = cannonError(err, domain.ErrCannonFireFailed)
+		// The ion cannon may have fired unless it answered that it refused the command
+		var cannonErr *domain.CannonError
+		if !errors.As(err, &cannonErr) || !cannonErr.Refused() {
+			err = domain.FireSent(err)
+		}
 		return 0, 0, err
 	}
 
 	return casualties, generation, nil
+}
 
+// noCannonError returns the error of an attack without available ion cannons.
+// If no ion cannon answered its status, the error of the first one is returned instead.
+func noCannonError(candidates []*domain.CannonCandidate) error {
+	if len(candidates) == 0 {
+		return domain.ErrNoCannonAvailable
+	}
+	for _, candidate := range candidates {
+		if candidate.Err == nil || errors.Is(candidate.Err, ErrCannonRetired) {
+			return domain.ErrNoCannonAvailable
+		}
+	}
+	return fmt.Errorf("failed to fire. No ion cannon answered its status: %w",
+		cannonError(candidates[0].Err, domain.ErrCannonStatusFailed))
+}
+
+// cannonError makes sure the error of an ion cannon is one of the domain errors,
+// wrapping it with the fallback error otherwise.
+func cannonError(err error, fallback error) error {
+	for _, target := range []error{
+		domain.ErrCannonUnreachable,
+		domain.ErrCannonTimeout,
+		domain.ErrCannonStatusFailed,
+		domain.ErrCannonFireFailed,
+	} {
+		if errors.Is(err, target) {
+			return err
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", domain.ErrCannonTimeout, err)
+	}
+	return fmt.Errorf("%w: %s", fallback, err)
 }
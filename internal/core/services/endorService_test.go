@@ -1,9 +1,16 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonClient"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/logger"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
@@ -17,30 +24,32 @@ func TestEndorService_Attack(t *testing.T) {
 
 	// Create an instance of the mock
 	mockIonCannonV1 := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
 		// Mock the CheckStatus function as needed
-		CheckStatusFunc: func() (*domain.IonCannon, error) {
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
 			return &domain.IonCannon{Available: true, Generation: 1}, nil
 		},
 
 		// Mock the FireCommand function as needed
-		FireCommandFunc: func(targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
 			return enemies, 1, nil
 		},
 	}
 	mockIonCannonV2 := &mocks.IonCannonClientMock{
+		CannonID: "cannon-2",
 		// Mock the CheckStatus function as needed
-		CheckStatusFunc: func() (*domain.IonCannon, error) {
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
 			return &domain.IonCannon{Available: true, Generation: 2}, nil
 		},
 
 		// Mock the FireCommand function as needed
-		FireCommandFunc: func(targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
 			return enemies, 2, nil
 		},
 	}
 
 	// Create an instance of the EndorService with the mock IonCannon
-	endorService := NewEndorService(log, []adapters.IonCannon{mockIonCannonV1, mockIonCannonV2})
+	endorService := NewEndorService([]adapters.IonCannon{mockIonCannonV1, mockIonCannonV2}, WithLogger(log))
 
 	// Create a sample attack
 	attack := &domain.Radar{
@@ -58,13 +67,18 @@ func TestEndorService_Attack(t *testing.T) {
 	}
 
 	// Call the Attack function
-	report, err := endorService.Attack(attack)
+	report, err := endorService.Attack(context.Background(), attack)
 
 	// Assert the results
 	assert.NoError(t, err)
 	assert.NotNil(t, report)
 	assert.Equal(t, 5, report.Casualties)
 	assert.Equal(t, 1, report.Generation)
+	assert.Equal(t, "cannon-1", report.CannonID)
+	assert.Equal(t, domain.Soldier, report.EnemyType)
+	assert.Equal(t, 2, report.Candidates)
+	assert.Equal(t, []string{"distance-limit:100", "closest-enemies"}, report.Pipeline)
+	assert.GreaterOrEqual(t, report.Timings.Total, report.Timings.Fire)
 
 	// Assert the function calls on the mock
 	assert.Len(t, mockIonCannonV1.CheckStatusCallData, 1)
@@ -75,3 +89,548 @@ func TestEndorService_Attack(t *testing.T) {
 	assert.Equal(t, 20, mockIonCannonV1.FireCommandCallData[0].TargetY)
 	assert.Equal(t, 5, mockIonCannonV1.FireCommandCallData[0].Enemies)
 }
+
+func TestEndorService_Plan(t *testing.T) {
+	log := logger.NewLogger(logger.DEBUG, false)
+
+	mockIonCannonV1 := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: false, Generation: 1}, nil
+		},
+	}
+	mockIonCannonV2 := &mocks.IonCannonClientMock{
+		CannonID: "cannon-2",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 2}, nil
+		},
+	}
+
+	endorService := NewEndorService([]adapters.IonCannon{mockIonCannonV1, mockIonCannonV2}, WithLogger(log))
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+			{Coordinates: domain.NewCoordinates(5, 5), Enemies: &domain.Enemy{Type: domain.Mech, Number: 1}},
+		},
+	}
+
+	plan, err := endorService.Plan(context.Background(), attack)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, plan.Target.Coordinates.X)
+	assert.Equal(t, 5, plan.Target.Coordinates.Y)
+	assert.Len(t, plan.Candidates, 2)
+	assert.Equal(t, "cannon-1", plan.Candidates[0].ID)
+	assert.False(t, plan.Candidates[0].Available)
+	assert.Equal(t, "cannon-2", plan.Cannon.ID)
+
+	// A plan must never fire
+	assert.Len(t, mockIonCannonV1.FireCommandCallData, 0)
+	assert.Len(t, mockIonCannonV2.FireCommandCallData, 0)
+}
+
+func TestEndorService_Confirm(t *testing.T) {
+	log := logger.NewLogger(logger.DEBUG, false)
+
+	available := true
+	mockIonCannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: available, Generation: 1}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+			return enemies, 1, nil
+		},
+	}
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+
+	t.Run("fires the plan only once", func(t *testing.T) {
+		mockIonCannon.FireCommandCallData = nil
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannon}, WithLogger(log))
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, plan.ID)
+
+		report, err := endorService.Confirm(context.Background(), plan.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Casualties)
+
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.ErrorIs(t, err, ErrPlanAlreadyExecuted)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 1)
+	})
+
+	t.Run("rejects unknown and expired plans", func(t *testing.T) {
+		mockIonCannon.FireCommandCallData = nil
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannon}, WithLogger(log), WithPlanTTL(time.Nanosecond))
+
+		_, err := endorService.Confirm(context.Background(), "unknown")
+		assert.ErrorIs(t, err, ErrPlanNotFound)
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.ErrorIs(t, err, ErrPlanExpired)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 0)
+	})
+
+	t.Run("does not fire when the cannon is no longer available", func(t *testing.T) {
+		mockIonCannon.FireCommandCallData = nil
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannon}, WithLogger(log))
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+
+		available = false
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.Error(t, err)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 0)
+
+		// The plan was not fired so it can be confirmed again
+		available = true
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 1)
+	})
+
+	t.Run("does not fire a cannon disabled since the plan", func(t *testing.T) {
+		mockIonCannon.FireCommandCallData = nil
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannon}, WithLogger(log))
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+
+		_, err = endorService.Fleet().SetEnabled("cannon-1", false)
+		assert.NoError(t, err)
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.ErrorIs(t, err, ErrCannonRetired)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 0)
+	})
+
+	t.Run("does not fire a cannon removed since the plan", func(t *testing.T) {
+		cannon := &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: mockIonCannon.CheckStatusFunc}
+		endorService := NewEndorService([]adapters.IonCannon{cannon}, WithLogger(log))
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+
+		assert.NoError(t, endorService.Fleet().Remove("cannon-1"))
+		_, err = endorService.Confirm(context.Background(), plan.ID)
+		assert.ErrorIs(t, err, ErrCannonRetired)
+		assert.Empty(t, cannon.FireCommandCallData)
+	})
+}
+
+func TestEndorService_AttackWaitForCannon(t *testing.T) {
+	checks := 0
+	mockIonCannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			checks++
+			// Recharging for the first two checks
+			return &domain.IonCannon{Available: checks > 2, Generation: 1}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+			return enemies, 1, nil
+		},
+	}
+	endorService := NewEndorService([]adapters.IonCannon{mockIonCannon})
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+
+	t.Run("fails immediately without wait", func(t *testing.T) {
+		_, err := endorService.Attack(context.Background(), attack)
+		assert.ErrorIs(t, err, domain.ErrNoCannonAvailable)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 0)
+	})
+
+	t.Run("waits for the first available cannon", func(t *testing.T) {
+		checks = 0
+		attack.MaxWait = 5 * time.Second
+
+		report, err := endorService.Attack(context.Background(), attack)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Casualties)
+		assert.Greater(t, report.WaitTime, time.Duration(0))
+		assert.Equal(t, 3, checks)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 1)
+	})
+
+	t.Run("gives up after the deadline", func(t *testing.T) {
+		checks = -100
+		mockIonCannon.FireCommandCallData = nil
+		attack.MaxWait = 200 * time.Millisecond
+
+		start := time.Now()
+		_, err := endorService.Attack(context.Background(), attack)
+		assert.ErrorIs(t, err, domain.ErrNoCannonAvailable)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Len(t, mockIonCannon.FireCommandCallData, 0)
+	})
+}
+
+func TestEndorService_AttackErrors(t *testing.T) {
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+	available := func(ctx context.Context) (*domain.IonCannon, error) {
+		return &domain.IonCannon{Available: true, Generation: 1}, nil
+	}
+
+	tests := []struct {
+		name     string
+		attack   *domain.Radar
+		cannon   *mocks.IonCannonClientMock
+		expected error
+		fireSent bool // whether the ion cannon may have fired
+	}{
+		{
+			name:     "no valid target",
+			attack:   &domain.Radar{Protocols: []domain.ProtocolType{domain.AvoidMech}, Scan: []*domain.Scan{{Coordinates: domain.NewCoordinates(0, 10), Enemies: &domain.Enemy{Type: domain.Mech, Number: 1}}}},
+			cannon:   &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: available},
+			expected: domain.ErrNoValidTarget,
+		},
+		{
+			name:   "status failures are kept",
+			attack: attack,
+			cannon: &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+				return nil, fmt.Errorf("%w: i/o timeout", domain.ErrCannonTimeout)
+			}},
+			expected: domain.ErrCannonTimeout,
+		},
+		{
+			name:   "unknown status failures",
+			attack: attack,
+			cannon: &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+				return nil, fmt.Errorf("boom")
+			}},
+			expected: domain.ErrCannonStatusFailed,
+		},
+		{
+			name:   "fire failures",
+			attack: attack,
+			cannon: &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: available, FireCommandFunc: func(context.Context, int, int, int) (int, int, error) {
+				return 0, 0, fmt.Errorf("boom")
+			}},
+			expected: domain.ErrCannonFireFailed,
+			fireSent: true,
+		},
+		{
+			name:   "fire commands refused by the cannon",
+			attack: attack,
+			cannon: &mocks.IonCannonClientMock{CannonID: "cannon-1", CheckStatusFunc: available, FireCommandFunc: func(context.Context, int, int, int) (int, int, error) {
+				return 0, 0, &domain.CannonError{Op: domain.ErrCannonFireFailed, StatusCode: 409, Code: domain.CannonErrorRecharging}
+			}},
+			expected: domain.ErrCannonRecharging,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endorService := NewEndorService([]adapters.IonCannon{tt.cannon})
+
+			_, err := endorService.Attack(context.Background(), tt.attack)
+			assert.ErrorIs(t, err, tt.expected)
+			assert.Equal(t, tt.fireSent, errors.Is(err, domain.ErrFireSent))
+		})
+	}
+}
+
+func TestEndorService_AttackConcurrent(t *testing.T) {
+	// newMock returns a cannon that fails if it is fired while it is already firing.
+	newMock := func(gen int) *mocks.IonCannonClientMock {
+		var firing int32
+		return &mocks.IonCannonClientMock{
+			CannonID: fmt.Sprintf("cannon-%d", gen),
+			CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+				return &domain.IonCannon{Available: true, Generation: gen}, nil
+			},
+			FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+				if !atomic.CompareAndSwapInt32(&firing, 0, 1) {
+					return 0, 0, fmt.Errorf("ion cannon double-tasked")
+				}
+				defer atomic.StoreInt32(&firing, 0)
+				time.Sleep(50 * time.Millisecond)
+				return enemies, gen, nil
+			},
+		}
+	}
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+
+	attackConcurrently := func(endorService *EndorService, n int) []*domain.Report {
+		reports := make([]*domain.Report, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				report, err := endorService.Attack(context.Background(), attack)
+				assert.NoError(t, err)
+				reports[i] = report
+			}(i)
+		}
+		wg.Wait()
+		return reports
+	}
+
+	t.Run("concurrent attacks pick different cannons", func(t *testing.T) {
+		mockIonCannonV1, mockIonCannonV2 := newMock(1), newMock(2)
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannonV1, mockIonCannonV2})
+
+		reports := attackConcurrently(endorService, 2)
+
+		assert.Len(t, mockIonCannonV1.FireCommandCallData, 1)
+		assert.Len(t, mockIonCannonV2.FireCommandCallData, 1)
+		assert.ElementsMatch(t, []int{1, 2}, []int{reports[0].Generation, reports[1].Generation})
+	})
+
+	t.Run("concurrent attacks queue for a reserved cannon", func(t *testing.T) {
+		mockIonCannon := newMock(1)
+		endorService := NewEndorService([]adapters.IonCannon{mockIonCannon})
+
+		attackConcurrently(endorService, 3)
+
+		assert.Len(t, mockIonCannon.FireCommandCallData, 3)
+	})
+}
+
+func TestEndorService_AttackCasualtyEstimate(t *testing.T) {
+	casualties := 10
+	mockIonCannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 2}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (int, int, error) {
+			return casualties, 2, nil
+		},
+	}
+	model := domain.CasualtyModel{
+		KillRate:           map[domain.EnemyType]float64{domain.Soldier: 0.5},
+		GenerationFactor:   1,
+		DeviationThreshold: 0.2,
+	}
+	endorService := NewEndorService([]adapters.IonCannon{mockIonCannon}, WithCasualtyModel(model))
+
+	attack := &domain.Radar{
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 10}},
+		},
+	}
+
+	plan, err := endorService.Plan(context.Background(), attack)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, plan.ExpectedCasualties)
+
+	report, err := endorService.Attack(context.Background(), attack)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, report.ExpectedCasualties)
+	assert.Equal(t, 0.0, report.CasualtyDeviation)
+	assert.False(t, report.Anomalous)
+
+	casualties = 5
+	report, err = endorService.Attack(context.Background(), attack)
+	assert.NoError(t, err)
+	assert.Equal(t, -0.5, report.CasualtyDeviation)
+	assert.True(t, report.Anomalous)
+}
+
+func TestEndorService_StatusFanOut(t *testing.T) {
+	var hang, cancelled int32
+	// newMock returns a cannon that hangs until the call is cancelled when hang is set.
+	newMock := func(id string, gen int, hangs bool) *mocks.IonCannonClientMock {
+		return &mocks.IonCannonClientMock{
+			CannonID: id,
+			CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+				if hangs && atomic.LoadInt32(&hang) == 1 {
+					<-ctx.Done()
+					atomic.AddInt32(&cancelled, 1)
+					return nil, ctx.Err()
+				}
+				return &domain.IonCannon{Available: true, Generation: gen}, nil
+			},
+			FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (int, int, error) {
+				return enemies, gen, nil
+			},
+		}
+	}
+	attack := &domain.Radar{
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 10}},
+		},
+	}
+
+	t.Run("hung cannons time out", func(t *testing.T) {
+		atomic.StoreInt32(&hang, 1)
+		endorService := NewEndorService([]adapters.IonCannon{newMock("cannon-1", 1, true), newMock("cannon-2", 2, false)},
+			WithStatusTimeout(50*time.Millisecond))
+
+		plan, err := endorService.Plan(context.Background(), attack)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, plan.Candidates[0].Err, domain.ErrCannonTimeout)
+		assert.Equal(t, "cannon-2", plan.Cannon.ID)
+	})
+
+	t.Run("early selection does not wait for worse cannons", func(t *testing.T) {
+		atomic.StoreInt32(&hang, 0)
+		endorService := NewEndorService([]adapters.IonCannon{newMock("cannon-1", 1, false), newMock("cannon-2", 2, true)},
+			WithStatusTimeout(5*time.Second), WithEarlySelection(true))
+
+		// The generations are unknown on the first attack, every cannon is awaited
+		report, err := endorService.Attack(context.Background(), attack)
+		assert.NoError(t, err)
+		assert.Equal(t, "cannon-1", report.CannonID)
+		assert.Equal(t, 2, report.Candidates)
+
+		atomic.StoreInt32(&hang, 1)
+		atomic.StoreInt32(&cancelled, 0)
+		start := time.Now()
+		report, err = endorService.Attack(context.Background(), attack)
+		assert.NoError(t, err)
+		assert.Equal(t, "cannon-1", report.CannonID)
+		assert.Less(t, time.Since(start), time.Second)
+		// Only the cannon that answered is counted
+		assert.Equal(t, 1, report.Candidates)
+
+		// The status check of the worse cannon is cancelled
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&cancelled) == 1 }, time.Second, time.Millisecond)
+	})
+}
+
+// TestEndorService_AttackSimulatorCassettes replays the traffic of two attacks against the 3 ion cannons of the simulator,
+// recorded with `make sim` and `RECORD_CASSETTES=1 go test ./internal/core/services -run Cassettes`.
+func TestEndorService_AttackSimulatorCassettes(t *testing.T) {
+	var ionCannons []adapters.IonCannon
+	for i := 1; i <= 3; i++ {
+		cassette := mocks.NewCassette(t, fmt.Sprintf("testdata/sim-ion-cannon-%d.json", i))
+		url := fmt.Sprintf("http://localhost:300%d", i)
+		ionCannons = append(ionCannons, ionCannonClient.NewIonCannonClient(url,
+			ionCannonClient.WithID(fmt.Sprintf("cannon-%d", i)), ionCannonClient.WithTransportMiddleware(cassette.Wrap)))
+	}
+	endorService := NewEndorService(ionCannons)
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(0, 40), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 10}},
+		},
+	}
+
+	// The first generation is fired first, then it is recharging
+	report, err := endorService.Attack(context.Background(), attack)
+	assert.NoError(t, err)
+	assert.Equal(t, "cannon-1", report.CannonID)
+	assert.Equal(t, 10, report.Casualties)
+
+	report, err = endorService.Attack(context.Background(), attack)
+	assert.NoError(t, err)
+	assert.Equal(t, "cannon-2", report.CannonID)
+	assert.Equal(t, 2, report.Generation)
+}
+
+func TestEndorService_ForgetsGenerationOfRetiredCannons(t *testing.T) {
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 1}, nil
+		},
+	}
+	endorService := NewEndorService([]adapters.IonCannon{cannon}, WithEarlySelection(true))
+
+	endorService.checkStatus(context.Background(), endorService.Fleet().IonCannons(), true)
+	generation, ok := endorService.generations.Load("cannon-1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, generation)
+
+	// A cannon registered later with the same ID may be of another generation
+	assert.NoError(t, endorService.Fleet().Remove("cannon-1"))
+	_, ok = endorService.generations.Load("cannon-1")
+	assert.False(t, ok)
+}
+
+func TestEndorService_ClosesRetiredCannonsOnceIdle(t *testing.T) {
+	firing, fired := make(chan struct{}), make(chan struct{})
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 1}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+			close(firing)
+			<-fired
+			return enemies, 1, nil
+		},
+	}
+	endorService := NewEndorService([]adapters.IonCannon{cannon})
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+	done := make(chan error)
+	go func() {
+		_, err := endorService.Attack(context.Background(), attack)
+		done <- err
+	}()
+	<-firing
+
+	// The client is closed once the attack firing the removed cannon is done
+	assert.NoError(t, endorService.Fleet().Remove("cannon-1"))
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, cannon.IsClosed())
+
+	close(fired)
+	assert.NoError(t, <-done)
+	assert.Eventually(t, cannon.IsClosed, time.Second, time.Millisecond)
+}
+
+func TestEndorService_DoesNotFireCannonsRetiredWhilePlanning(t *testing.T) {
+	var endorService *EndorService
+	cannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			// Removed after the snapshot of the fleet, before it is reserved
+			endorService.Fleet().Remove("cannon-1")
+			return &domain.IonCannon{Available: true, Generation: 1}, nil
+		},
+	}
+	endorService = NewEndorService([]adapters.IonCannon{cannon})
+
+	attack := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+	_, err := endorService.Attack(context.Background(), attack)
+	assert.ErrorIs(t, err, domain.ErrNoCannonAvailable)
+	assert.Empty(t, cannon.FireCommandCallData)
+	assert.Eventually(t, cannon.IsClosed, time.Second, time.Millisecond)
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sync"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+var (
+	ErrCannonNotFound = errors.New("ion cannon not found")
+	ErrCannonExists   = errors.New("ion cannon already exists")
+	ErrInvalidCannon  = errors.New("invalid ion cannon")
+	// ErrCannonRetired is returned when an ion cannon was removed from the fleet, disabled or replaced
+	// after it was chosen to fire.
+	ErrCannonRetired = errors.New("ion cannon removed from the fleet, disabled or replaced")
+)
+
+// cannonIDPattern restricts the identifiers of the ion cannons to the characters of their URLs, as the IDs
+// default to them. IDs with "/" must be escaped as "%2F" in the paths of the admin endpoints.
+var cannonIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:/-]{1,256}$`)
+
+// cannonSchemes are the schemes of the URLs of the ion cannons, which select the protocol of their client.
+var cannonSchemes = map[string]bool{"http": true, "https": true, "grpc": true}
+
+// IonCannonFactory creates the client of an ion cannon.
+type IonCannonFactory func(definition *domain.CannonDefinition) (adapters.IonCannon, error)
+
+// FleetCannon is an ion cannon registered in the fleet.
+type FleetCannon struct {
+	ID       string
+	URL      string
+	Position *domain.Coordinate
+	Tags     []string
+	TLS      *domain.CannonTLS
+	Secret   *domain.CannonSecret
+	Source   string // source managing the cannon, empty if registered or added manually
+	Enabled  bool
+	Client   adapters.IonCannon
+}
+
+// FleetDiff holds the IDs of the ion cannons changed by a synchronisation of the fleet.
+type FleetDiff struct {
+	Added   []string
+	Removed []string
+	Updated []string
+}
+
+// Empty returns true if the synchronisation did not change the fleet.
+func (d FleetDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Updated) == 0
+}
+
+// Fleet is the concurrency-safe registry of the ion cannons the service can fire.
+// Entries are never modified in place, so attacks working with a snapshot of the fleet
+// are not disrupted by changes.
+type Fleet struct {
+	mu        sync.RWMutex
+	factory   IonCannonFactory
+	cannons   []*FleetCannon
+	onRetired []func(id string)
+	waitIdle  func(id string) // blocks until no attack fires the ion cannon, nil if the fleet is not used by a service
+}
+
+// NewFleet creates an empty fleet. The factory is used to create the clients of the ion cannons
+// added at runtime, a fleet without factory only accepts registered clients.
+func NewFleet(factory IonCannonFactory) *Fleet {
+	return &Fleet{factory: factory}
+}
+
+// OnRetired registers a function called with the ID of every ion cannon removed from the fleet, or whose
+// client is replaced by a synchronisation, so what is known about the cannon can be forgotten.
+func (f *Fleet) OnRetired(fn func(id string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onRetired = append(f.onRetired, fn)
+}
+
+// retire calls the functions registered with OnRetired for every ion cannon, and closes their clients once
+// no attack is firing them. It must be called without the lock held.
+func (f *Fleet) retire(cannons ...*FleetCannon) {
+	f.mu.RLock()
+	onRetired := f.onRetired
+	waitIdle := f.waitIdle
+	f.mu.RUnlock()
+
+	for _, c := range cannons {
+		for _, fn := range onRetired {
+			fn(c.ID)
+		}
+
+		c := c
+		go func() {
+			if waitIdle != nil {
+				waitIdle(c.ID)
+			}
+			c.Client.Close()
+		}()
+	}
+}
+
+// setWaitIdle sets the function blocking until no attack fires the ion cannon with the ID,
+// so the clients of the retired cannons are only closed once they are idle.
+func (f *Fleet) setWaitIdle(fn func(id string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waitIdle = fn
+}
+
+// isActive returns true if the client is the one of an enabled ion cannon of the fleet, so it can be fired.
+func (f *Fleet) isActive(client adapters.IonCannon) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, c := range f.cannons {
+		if c.Client == client {
+			return c.Enabled
+		}
+	}
+	return false
+}
+
+// Close closes the clients of all the ion cannons of the fleet. It must only be called once no attack is running.
+func (f *Fleet) Close() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var firstErr error
+	for _, c := range f.cannons {
+		if err := c.Client.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close ion cannon %s: %w", c.ID, err)
+		}
+	}
+	return firstErr
+}
+
+// Register adds an enabled ion cannon with an existing client to the fleet, without checking its connectivity.
+func (f *Fleet) Register(id string, url string, client adapters.IonCannon) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.find(id) >= 0 {
+		return fmt.Errorf("%w: %s", ErrCannonExists, id)
+	}
+	f.cannons = append(f.cannons[:len(f.cannons):len(f.cannons)], &FleetCannon{ID: id, URL: url, Enabled: true, Client: client})
+	return nil
+}
+
+// Add validates the ion cannon, creates its client and checks it is reachable before adding it to the fleet.
+// If the ID is empty, the URL is used as identifier. The errors of the connectivity check are domain errors.
+func (f *Fleet) Add(ctx context.Context, definition *domain.CannonDefinition) (*FleetCannon, error) {
+	d := *definition
+	if d.ID == "" {
+		d.ID = d.URL
+	}
+	if err := validateCannon(d.ID, d.URL); err != nil {
+		return nil, err
+	}
+	if f.factory == nil {
+		return nil, fmt.Errorf("%w: the fleet does not support adding ion cannons", ErrInvalidCannon)
+	}
+
+	f.mu.RLock()
+	exists := f.find(d.ID) >= 0
+	f.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrCannonExists, d.ID)
+	}
+
+	client, err := f.factory(&d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCannon, err)
+	}
+	if _, err := client.CheckStatus(ctx); err != nil {
+		client.Close()
+		return nil, cannonError(err, domain.ErrCannonUnreachable)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.find(d.ID) >= 0 {
+		client.Close()
+		return nil, fmt.Errorf("%w: %s", ErrCannonExists, d.ID)
+	}
+	cannon := &FleetCannon{ID: d.ID, URL: d.URL, Position: d.Position, Tags: d.Tags, TLS: d.TLS, Secret: d.Secret, Enabled: true, Client: client}
+	f.cannons = append(f.cannons[:len(f.cannons):len(f.cannons)], cannon)
+
+	added := *cannon
+	return &added, nil
+}
+
+// Remove removes the ion cannon from the fleet. Attacks already firing it are not affected,
+// its client is closed once they are done.
+func (f *Fleet) Remove(id string) error {
+	f.mu.Lock()
+	i := f.find(id)
+	if i < 0 {
+		f.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrCannonNotFound, id)
+	}
+
+	removed := f.cannons[i]
+	cannons := make([]*FleetCannon, 0, len(f.cannons)-1)
+	cannons = append(cannons, f.cannons[:i]...)
+	f.cannons = append(cannons, f.cannons[i+1:]...)
+	f.mu.Unlock()
+
+	f.retire(removed)
+	return nil
+}
+
+// SetEnabled enables or disables the ion cannon. Disabled cannons are never fired.
+func (f *Fleet) SetEnabled(id string, enabled bool) (*FleetCannon, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.find(id)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCannonNotFound, id)
+	}
+
+	cannon := *f.cannons[i]
+	cannon.Enabled = enabled
+
+	cannons := make([]*FleetCannon, len(f.cannons))
+	copy(cannons, f.cannons)
+	cannons[i] = &cannon
+	f.cannons = cannons
+
+	return &cannon, nil
+}
+
+// Sync makes the ion cannons managed by the source match the definitions: new cannons are added,
+// missing ones removed and changed ones updated, keeping whether they are enabled. A new client is only
+// created when the URL, the TLS configuration or the secret of a cannon changes.
+// Cannons of other sources are never modified. The fleet is not changed if any definition is invalid.
+// The cannons removed or with a new client are retired.
+func (f *Fleet) Sync(source string, definitions []*domain.CannonDefinition) (FleetDiff, error) {
+	diff, retired, err := f.sync(source, definitions)
+	f.retire(retired...)
+	return diff, err
+}
+
+// sync applies the definitions of the source and returns the cannons to retire.
+func (f *Fleet) sync(source string, definitions []*domain.CannonDefinition) (diff FleetDiff, retired []*FleetCannon, err error) {
+	if source == "" {
+		return diff, nil, fmt.Errorf("%w: the source of the definitions is required", ErrInvalidCannon)
+	}
+	if f.factory == nil {
+		return diff, nil, fmt.Errorf("%w: the fleet does not support adding ion cannons", ErrInvalidCannon)
+	}
+
+	ids := make(map[string]bool, len(definitions))
+	for _, d := range definitions {
+		if err := validateCannon(d.ID, d.URL); err != nil {
+			return diff, nil, fmt.Errorf("%s: %w", d.ID, err)
+		}
+		if ids[d.ID] {
+			return diff, nil, fmt.Errorf("%w: %s is defined twice", ErrCannonExists, d.ID)
+		}
+		ids[d.ID] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := make(map[string]*FleetCannon, len(f.cannons))
+	for _, c := range f.cannons {
+		current[c.ID] = c
+	}
+
+	// The clients created are closed if a definition is invalid, as the fleet is not changed
+	var created []adapters.IonCannon
+	defer func() {
+		if err != nil {
+			for _, client := range created {
+				client.Close()
+			}
+		}
+	}()
+
+	cannons := make([]*FleetCannon, 0, len(f.cannons)+len(definitions))
+	for _, c := range f.cannons {
+		switch {
+		case c.Source != source:
+			cannons = append(cannons, c)
+		case ids[c.ID]:
+			// Kept, it is replaced or appended below in the order of the definitions
+		default:
+			diff.Removed = append(diff.Removed, c.ID)
+			retired = append(retired, c)
+		}
+	}
+
+	for _, d := range definitions {
+		existing, ok := current[d.ID]
+		if ok && existing.Source != source {
+			return FleetDiff{}, nil, fmt.Errorf("%w: %s is managed by another source", ErrCannonExists, d.ID)
+		}
+		if ok && existing.URL == d.URL && reflect.DeepEqual(existing.Position, d.Position) &&
+			reflect.DeepEqual(existing.Tags, d.Tags) && existing.clientFor(d) != nil {
+			cannons = append(cannons, existing)
+			continue
+		}
+
+		client := existing.clientFor(d)
+		if client == nil {
+			client, err = f.factory(d)
+			if err != nil {
+				return FleetDiff{}, nil, fmt.Errorf("%w: %s", ErrInvalidCannon, err)
+			}
+			created = append(created, client)
+			if ok {
+				retired = append(retired, existing)
+			}
+		}
+
+		cannon := &FleetCannon{ID: d.ID, URL: d.URL, Position: d.Position, Tags: d.Tags, TLS: d.TLS, Secret: d.Secret, Source: source, Enabled: true, Client: client}
+		if ok {
+			cannon.Enabled = existing.Enabled
+			diff.Updated = append(diff.Updated, d.ID)
+		} else {
+			diff.Added = append(diff.Added, d.ID)
+		}
+		cannons = append(cannons, cannon)
+	}
+
+	f.cannons = cannons
+	return diff, retired, nil
+}
+
+// clientFor returns the client of the cannon if it can be reused for the definition, or nil.
+func (c *FleetCannon) clientFor(d *domain.CannonDefinition) adapters.IonCannon {
+	if c == nil || c.URL != d.URL || !reflect.DeepEqual(c.TLS, d.TLS) || !reflect.DeepEqual(c.Secret, d.Secret) {
+		return nil
+	}
+	return c.Client
+}
+
+// Get returns the ion cannon with the given ID.
+func (f *Fleet) Get(id string) (*FleetCannon, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	i := f.find(id)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCannonNotFound, id)
+	}
+	cannon := *f.cannons[i]
+	return &cannon, nil
+}
+
+// List returns all the ion cannons of the fleet, enabled or not.
+func (f *Fleet) List() []FleetCannon {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	cannons := make([]FleetCannon, len(f.cannons))
+	for i, c := range f.cannons {
+		cannons[i] = *c
+	}
+	return cannons
+}
+
+// IonCannons returns a snapshot of the clients of the enabled ion cannons.
+func (f *Fleet) IonCannons() []adapters.IonCannon {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	ionCannons := make([]adapters.IonCannon, 0, len(f.cannons))
+	for _, c := range f.cannons {
+		if c.Enabled {
+			ionCannons = append(ionCannons, c.Client)
+		}
+	}
+	return ionCannons
+}
+
+// find returns the index of the ion cannon with the given ID or -1. It must be called with the lock held.
+func (f *Fleet) find(id string) int {
+	for i, c := range f.cannons {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateCannon checks the identifier and the URL of an ion cannon.
+func validateCannon(id string, cannonURL string) error {
+	if !cannonIDPattern.MatchString(id) {
+		return fmt.Errorf("%w: id must be 1 to 256 letters, digits or ._:/- characters", ErrInvalidCannon)
+	}
+
+	u, err := url.Parse(cannonURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCannon, err)
+	}
+	if !cannonSchemes[u.Scheme] || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http, https or grpc URL", ErrInvalidCannon)
+	}
+	return nil
+}
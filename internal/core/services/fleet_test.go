@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFleet(t *testing.T) {
+	reachable := func(ctx context.Context) (*domain.IonCannon, error) {
+		return &domain.IonCannon{Generation: 1, Available: true}, nil
+	}
+	unreachable := func(ctx context.Context) (*domain.IonCannon, error) { return nil, errors.New("connection refused") }
+
+	fleet := NewFleet(func(d *domain.CannonDefinition) (adapters.IonCannon, error) {
+		client := &mocks.IonCannonClientMock{CannonID: d.ID, CheckStatusFunc: reachable}
+		if d.URL == "http://down:3000" {
+			client.CheckStatusFunc = unreachable
+		}
+		return client, nil
+	})
+
+	var retired []string
+	fleet.OnRetired(func(id string) { retired = append(retired, id) })
+
+	initial := &mocks.IonCannonClientMock{CannonID: "cannon-1"}
+	assert.NoError(t, fleet.Register("cannon-1", "http://cannon-1:3000", initial))
+	assert.ErrorIs(t, fleet.Register("cannon-1", "http://cannon-1:3000", initial), ErrCannonExists)
+
+	// Add defaults the ID to the URL and checks the connectivity
+	cannon, err := fleet.Add(context.Background(), &domain.CannonDefinition{ID: "", URL: "http://cannon-2:3000"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://cannon-2:3000", cannon.ID)
+	assert.True(t, cannon.Enabled)
+
+	_, err = fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon-3", URL: "http://down:3000"})
+	assert.ErrorIs(t, err, domain.ErrCannonUnreachable)
+	_, err = fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon-3", URL: "ftp://cannon-3"})
+	assert.ErrorIs(t, err, ErrInvalidCannon)
+	_, err = fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon 3", URL: "http://cannon-3:3000"})
+	assert.ErrorIs(t, err, ErrInvalidCannon)
+	_, err = fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon-1", URL: "http://cannon-1:3000"})
+	assert.ErrorIs(t, err, ErrCannonExists)
+
+	// Snapshots are not affected by later changes
+	snapshot := fleet.IonCannons()
+	assert.Len(t, snapshot, 2)
+
+	cannon, err = fleet.SetEnabled("cannon-1", false)
+	assert.NoError(t, err)
+	assert.False(t, cannon.Enabled)
+	assert.Len(t, fleet.IonCannons(), 1)
+	assert.Len(t, fleet.List(), 2)
+
+	assert.Empty(t, retired)
+	assert.NoError(t, fleet.Remove("http://cannon-2:3000"))
+	assert.ErrorIs(t, fleet.Remove("http://cannon-2:3000"), ErrCannonNotFound)
+	assert.Equal(t, []string{"http://cannon-2:3000"}, retired)
+	assert.Empty(t, fleet.IonCannons())
+	assert.Len(t, snapshot, 2)
+	assert.Equal(t, initial, snapshot[0])
+
+	_, err = fleet.SetEnabled("unknown", true)
+	assert.ErrorIs(t, err, ErrCannonNotFound)
+}
+
+func TestFleet_WithoutFactory(t *testing.T) {
+	fleet := NewFleet(nil)
+
+	_, err := fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon-1", URL: "http://cannon-1:3000"})
+	assert.ErrorIs(t, err, ErrInvalidCannon)
+	assert.Empty(t, fleet.List())
+}
+
+func TestFleet_SyncRetiresReplacedCannons(t *testing.T) {
+	fleet := NewFleet(func(d *domain.CannonDefinition) (adapters.IonCannon, error) {
+		return &mocks.IonCannonClientMock{CannonID: d.ID}, nil
+	})
+	var retired []string
+	fleet.OnRetired(func(id string) { retired = append(retired, id) })
+
+	_, err := fleet.Sync("file", []*domain.CannonDefinition{
+		{ID: "cannon-1", URL: "http://cannon-1:3000"},
+		{ID: "cannon-2", URL: "http://cannon-2:3000"},
+		{ID: "cannon-3", URL: "http://cannon-3:3000"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, retired)
+
+	// Only the cannons removed or with a new client are retired
+	diff, err := fleet.Sync("file", []*domain.CannonDefinition{
+		{ID: "cannon-1", URL: "http://cannon-1:3000", Tags: []string{"north"}},
+		{ID: "cannon-2", URL: "http://cannon-2:4000"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cannon-1", "cannon-2"}, diff.Updated)
+	assert.ElementsMatch(t, []string{"cannon-2", "cannon-3"}, retired)
+
+	// The fleet is not changed by invalid definitions, nothing is retired
+	retired = nil
+	_, err = fleet.Sync("file", []*domain.CannonDefinition{{ID: "cannon 1", URL: "http://cannon-1:3000"}})
+	assert.ErrorIs(t, err, ErrInvalidCannon)
+	assert.Empty(t, retired)
+}
+
+func TestFleet_ClosesClients(t *testing.T) {
+	var clients []*mocks.IonCannonClientMock
+	fleet := NewFleet(func(d *domain.CannonDefinition) (adapters.IonCannon, error) {
+		client := &mocks.IonCannonClientMock{CannonID: d.ID}
+		if d.URL == "http://down:3000" {
+			client.CheckStatusFunc = func(ctx context.Context) (*domain.IonCannon, error) { return nil, errors.New("connection refused") }
+		}
+		clients = append(clients, client)
+		return client, nil
+	})
+
+	// The client of a cannon that can not be added is closed
+	_, err := fleet.Add(context.Background(), &domain.CannonDefinition{ID: "cannon-1", URL: "http://down:3000"})
+	assert.ErrorIs(t, err, domain.ErrCannonUnreachable)
+	assert.True(t, clients[0].IsClosed())
+
+	_, err = fleet.Sync("file", []*domain.CannonDefinition{
+		{ID: "cannon-1", URL: "http://cannon-1:3000"},
+		{ID: "cannon-2", URL: "http://cannon-2:3000"},
+	})
+	assert.NoError(t, err)
+	cannon1, cannon2 := clients[1], clients[2]
+
+	// The clients created by a failed synchronisation are closed, the ones of the fleet are kept
+	assert.NoError(t, fleet.Register("cannon-3", "http://cannon-3:3000", &mocks.IonCannonClientMock{CannonID: "cannon-3"}))
+	_, err = fleet.Sync("file", []*domain.CannonDefinition{
+		{ID: "cannon-1", URL: "http://cannon-1:4000"},
+		{ID: "cannon-3", URL: "http://cannon-3:3000"},
+	})
+	assert.ErrorIs(t, err, ErrCannonExists)
+	assert.True(t, clients[3].IsClosed())
+	assert.False(t, cannon1.IsClosed())
+
+	// The clients of the cannons removed or replaced are closed
+	_, err = fleet.Sync("file", []*domain.CannonDefinition{{ID: "cannon-1", URL: "http://cannon-1:4000"}})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return cannon1.IsClosed() && cannon2.IsClosed() }, time.Second, time.Millisecond)
+
+	assert.NoError(t, fleet.Close())
+	assert.True(t, clients[len(clients)-1].IsClosed())
+}
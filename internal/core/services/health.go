@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// DefaultMinReadyCannons is the default minimum number of reachable ion cannons for the service to be ready.
+const DefaultMinReadyCannons = 1
+
+// Readiness reports whether the service is ready to attack, with the status of every enabled ion cannon.
+type Readiness struct {
+	Ready        bool
+	ShuttingDown bool
+	MinCannons   int
+	Reachable    int                       // number of ion cannons answering their status, available or not
+	Cannons      []*domain.CannonCandidate // status of the enabled ion cannons, in the order of the fleet
+	Pool         PoolStats                 // usage of the worker pool before the status checks
+}
+
+// CheckReadiness checks the status of the enabled ion cannons of the fleet. The service is ready when
+// at least the minimum number of ion cannons are reachable, and never once its shutdown has started.
+// The usage of the worker pool is taken before the status checks, so it does not count them.
+func (m *EndorService) CheckReadiness(ctx context.Context) *Readiness {
+	readiness := &Readiness{
+		ShuttingDown: m.shuttingDown.Load(),
+		MinCannons:   m.minReadyCannons,
+		Cannons:      []*domain.CannonCandidate{},
+		Pool:         m.pool.Stats(),
+	}
+	if readiness.ShuttingDown {
+		return readiness
+	}
+
+	readiness.Cannons = m.checkStatus(ctx, m.fleet.IonCannons(), false)
+	for _, candidate := range readiness.Cannons {
+		if candidate.Err == nil {
+			readiness.Reachable++
+		}
+	}
+	readiness.Ready = readiness.Reachable >= readiness.MinCannons
+	return readiness
+}
+
+// Shutdown marks the service as shutting down, so it is no longer ready to receive traffic.
+// Attacks in flight and new attacks are not affected.
+func (m *EndorService) Shutdown() {
+	m.shuttingDown.Store(true)
+}
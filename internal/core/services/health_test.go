@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndorService_CheckReadiness(t *testing.T) {
+	recharging := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: false, Generation: 1}, nil
+		},
+	}
+	unreachable := &mocks.IonCannonClientMock{
+		CannonID: "cannon-2",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return nil, domain.ErrCannonUnreachable
+		},
+	}
+	available := &mocks.IonCannonClientMock{
+		CannonID: "cannon-3",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 2}, nil
+		},
+	}
+	cannons := []adapters.IonCannon{recharging, unreachable, available}
+
+	t.Run("ready with the minimum of reachable cannons", func(t *testing.T) {
+		svc := NewEndorService(cannons, WithMinReadyCannons(2))
+		readiness := svc.CheckReadiness(context.Background())
+
+		assert.True(t, readiness.Ready)
+		assert.Equal(t, 2, readiness.Reachable)
+		assert.Equal(t, 2, readiness.MinCannons)
+		assert.Len(t, readiness.Cannons, 3)
+		assert.False(t, readiness.Cannons[0].Available)
+		assert.ErrorIs(t, readiness.Cannons[1].Err, domain.ErrCannonUnreachable)
+		assert.Equal(t, 2, readiness.Cannons[2].Generation)
+		assert.Equal(t, DefaultMaxConcurrency, readiness.Pool.Capacity)
+		assert.Zero(t, readiness.Pool.InUse)
+	})
+
+	t.Run("not ready below the minimum of reachable cannons", func(t *testing.T) {
+		svc := NewEndorService(cannons, WithMinReadyCannons(3))
+
+		assert.False(t, svc.CheckReadiness(context.Background()).Ready)
+	})
+
+	t.Run("disabled cannons are not checked", func(t *testing.T) {
+		svc := NewEndorService(cannons)
+		_, err := svc.Fleet().SetEnabled("cannon-1", false)
+		assert.NoError(t, err)
+		_, err = svc.Fleet().SetEnabled("cannon-3", false)
+		assert.NoError(t, err)
+		readiness := svc.CheckReadiness(context.Background())
+
+		assert.False(t, readiness.Ready)
+		assert.Len(t, readiness.Cannons, 1)
+	})
+
+	t.Run("not ready once shutting down", func(t *testing.T) {
+		svc := NewEndorService(cannons)
+		assert.True(t, svc.CheckReadiness(context.Background()).Ready)
+
+		svc.Shutdown()
+		readiness := svc.CheckReadiness(context.Background())
+
+		assert.False(t, readiness.Ready)
+		assert.True(t, readiness.ShuttingDown)
+		assert.Empty(t, readiness.Cannons)
+	})
+}
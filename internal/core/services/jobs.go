@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// DefaultJobWorkers is the default number of attack jobs executed concurrently.
+const DefaultJobWorkers = 4
+
+// DefaultJobQueueSize is the default number of attack jobs waiting to be executed.
+const DefaultJobQueueSize = 1000
+
+// DefaultJobTTL is the default time finished attack jobs are kept before they are deleted.
+const DefaultJobTTL = 24 * time.Hour
+
+// jobSweepInterval is the time between the deletions of the expired attack jobs.
+const jobSweepInterval = time.Minute
+
+var (
+	// ErrJobQueueFull is returned when there is no room for more attack jobs.
+	ErrJobQueueFull = errors.New("attack job queue is full")
+	// ErrJobInterrupted is the error of the jobs that were running when the service stopped.
+	ErrJobInterrupted = errors.New("interrupted by a restart of the service")
+)
+
+// JobRunner executes attacks asynchronously with a fixed number of workers.
+// Every change of a job is persisted, so jobs survive a restart of the service.
+type JobRunner struct {
+	svc     *EndorService
+	store   adapters.JobStore
+	log     *zap.SugaredLogger
+	workers int
+	ttl     time.Duration
+	codeOf  func(error) string
+	queue   chan *domain.Job
+	stop    chan struct{}
+	mu      sync.Mutex // serializes the updates of the jobs in the store
+	wg      sync.WaitGroup
+}
+
+// JobOption configures the JobRunner.
+type JobOption func(*JobRunner)
+
+// WithJobTTL sets the time finished jobs are kept before they are deleted. Zero keeps them forever.
+func WithJobTTL(ttl time.Duration) JobOption {
+	return func(j *JobRunner) {
+		j.ttl = ttl
+	}
+}
+
+// WithJobErrorCode sets the function giving the machine-readable code of the error of a failed job,
+// stored with its message so the code is still known after a restart.
+func WithJobErrorCode(codeOf func(error) string) JobOption {
+	return func(j *JobRunner) {
+		j.codeOf = codeOf
+	}
+}
+
+// NewJobRunner creates a new instance of the JobRunner.
+func NewJobRunner(svc *EndorService, store adapters.JobStore, workers int, queueSize int, opts ...JobOption) *JobRunner {
+	if workers <= 0 {
+		workers = DefaultJobWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultJobQueueSize
+	}
+	j := &JobRunner{
+		svc:     svc,
+		store:   store,
+		log:     svc.log,
+		workers: workers,
+		ttl:     DefaultJobTTL,
+		codeOf:  func(error) string { return "" },
+		queue:   make(chan *domain.Job, queueSize),
+		stop:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Start recovers the jobs of a previous run and starts the workers.
+// Pending jobs are queued again. Jobs that were running are marked as failed, as we cannot
+// know if the ion cannon was fired and an attack must never be fired twice.
+// Finished jobs are deleted once they expire.
+func (j *JobRunner) Start() error {
+	if _, err := j.Sweep(time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired attack jobs: %w", err)
+	}
+
+	jobs, err := j.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to recover attack jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		switch job.Status {
+		case domain.JobRunning:
+			if err := j.fail(job, ErrJobInterrupted); err != nil {
+				return err
+			}
+		case domain.JobPending:
+			select {
+			case j.queue <- job:
+			default:
+				if err := j.fail(job, ErrJobQueueFull); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := 0; i < j.workers; i++ {
+		j.wg.Add(1)
+		go j.work()
+	}
+	if j.ttl > 0 {
+		j.wg.Add(1)
+		go j.sweep()
+	}
+	return nil
+}
+
+// Stop waits for the workers to finish the jobs they are running.
+// Queued jobs stay pending and are executed on the next Start.
+func (j *JobRunner) Stop() {
+	close(j.stop)
+	j.wg.Wait()
+}
+
+// Submit stores a new attack job and queues it for execution.
+func (j *JobRunner) Submit(radar *domain.Radar) (*domain.Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &domain.Job{
+		ID:        id,
+		Status:    domain.JobPending,
+		Radar:     radar,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := j.store.Save(job); err != nil {
+		return nil, fmt.Errorf("failed to store attack job: %w", err)
+	}
+
+	// The queued job is updated by the workers, the caller gets a copy.
+	submitted := *job
+
+	select {
+	case j.queue <- job:
+	default:
+		if err := j.fail(job, ErrJobQueueFull); err != nil {
+			j.log.Errorf("Failed to update job %s: %v\n", job.ID, err)
+		}
+		return nil, ErrJobQueueFull
+	}
+
+	return &submitted, nil
+}
+
+// Get returns the attack job with the given ID.
+func (j *JobRunner) Get(id string) (*domain.Job, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.store.Get(id)
+}
+
+// work executes the queued jobs until the runner is stopped.
+func (j *JobRunner) work() {
+	defer j.wg.Done()
+
+	for {
+		var job *domain.Job
+		select {
+		case <-j.stop:
+			return
+		case job = <-j.queue:
+		}
+
+		select {
+		case <-j.stop:
+			return
+		default:
+		}
+
+		if err := j.update(job, domain.JobRunning); err != nil {
+			// Without persisting the running state a restart could fire the attack twice.
+			j.log.Errorf("Failed to update job %s: %v\n", job.ID, err)
+			continue
+		}
+
+		report, err := j.svc.Attack(context.Background(), job.Radar)
+		if err != nil {
+			err = j.fail(job, err)
+		} else {
+			job.Report = report
+			err = j.update(job, domain.JobSucceeded)
+		}
+		if err != nil {
+			j.log.Errorf("Failed to update job %s: %v\n", job.ID, err)
+		}
+	}
+}
+
+// sweep deletes the expired jobs periodically until the runner is stopped.
+func (j *JobRunner) sweep() {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(jobSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case now := <-ticker.C:
+			if _, err := j.Sweep(now); err != nil {
+				j.log.Errorf("Failed to delete expired jobs: %v\n", err)
+			}
+		}
+	}
+}
+
+// Sweep deletes the finished jobs not updated within the TTL before now, and returns how many were deleted.
+// Pending and running jobs never expire.
+func (j *JobRunner) Sweep(now time.Time) (int, error) {
+	if j.ttl <= 0 {
+		return 0, nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	jobs, err := j.store.List()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, job := range jobs {
+		finished := job.Status == domain.JobSucceeded || job.Status == domain.JobFailed
+		if !finished || now.Sub(job.UpdatedAt) < j.ttl {
+			continue
+		}
+		if err := j.store.Delete(job.ID); err != nil && !errors.Is(err, domain.ErrJobNotFound) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// fail marks the job as failed with the error and its code, and persists it.
+func (j *JobRunner) fail(job *domain.Job, err error) error {
+	job.Error = err.Error()
+	job.ErrorCode = j.codeOf(err)
+	return j.update(job, domain.JobFailed)
+}
+
+// update changes the status of the job and persists it.
+func (j *JobRunner) update(job *domain.Job, status domain.JobStatus) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job.Status = status
+	job.UpdatedAt = time.Now()
+	return j.store.Save(job)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRunner(t *testing.T) {
+	mockIonCannon := &mocks.IonCannonClientMock{
+		CannonID: "cannon-1",
+		CheckStatusFunc: func(ctx context.Context) (*domain.IonCannon, error) {
+			return &domain.IonCannon{Available: true, Generation: 1}, nil
+		},
+		FireCommandFunc: func(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+			return enemies, 1, nil
+		},
+	}
+	endorService := NewEndorService([]adapters.IonCannon{mockIonCannon})
+
+	radar := &domain.Radar{
+		Protocols: []domain.ProtocolType{domain.ClosestEnemies},
+		Scan: []*domain.Scan{
+			{Coordinates: domain.NewCoordinates(10, 20), Enemies: &domain.Enemy{Type: domain.Soldier, Number: 5}},
+		},
+	}
+
+	waitForStatus := func(runner *JobRunner, id string, status domain.JobStatus) *domain.Job {
+		var job *domain.Job
+		assert.Eventually(t, func() bool {
+			var err error
+			job, err = runner.Get(id)
+			return err == nil && job.Status == status
+		}, time.Second, time.Millisecond)
+		return job
+	}
+
+	t.Run("executes submitted jobs", func(t *testing.T) {
+		runner := NewJobRunner(endorService, mocks.NewJobStoreMock(), 1, 10)
+		assert.NoError(t, runner.Start())
+		defer runner.Stop()
+
+		job, err := runner.Submit(radar)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JobPending, job.Status)
+
+		job = waitForStatus(runner, job.ID, domain.JobSucceeded)
+		assert.Equal(t, 5, job.Report.Casualties)
+
+		_, err = runner.Get("unknown")
+		assert.ErrorIs(t, err, domain.ErrJobNotFound)
+	})
+
+	t.Run("recovers jobs after a restart", func(t *testing.T) {
+		store := mocks.NewJobStoreMock(
+			&domain.Job{ID: "pending", Status: domain.JobPending, Radar: radar},
+			&domain.Job{ID: "running", Status: domain.JobRunning, Radar: radar},
+		)
+		mockIonCannon.FireCommandCallData = nil
+
+		runner := NewJobRunner(endorService, store, 1, 10)
+		assert.NoError(t, runner.Start())
+		defer runner.Stop()
+
+		waitForStatus(runner, "pending", domain.JobSucceeded)
+		job := waitForStatus(runner, "running", domain.JobFailed)
+		assert.Equal(t, ErrJobInterrupted.Error(), job.Error)
+
+		// Only the pending job was fired
+		assert.Len(t, mockIonCannon.FireCommandCallData, 1)
+	})
+
+	t.Run("stores the code of the error of failed jobs", func(t *testing.T) {
+		codeOf := func(err error) string {
+			if errors.Is(err, domain.ErrNoCannonAvailable) {
+				return "no_cannon_available"
+			}
+			return "unknown"
+		}
+		runner := NewJobRunner(NewEndorService(nil), mocks.NewJobStoreMock(), 1, 10, WithJobErrorCode(codeOf))
+		assert.NoError(t, runner.Start())
+		defer runner.Stop()
+
+		job, err := runner.Submit(radar)
+		assert.NoError(t, err)
+
+		job = waitForStatus(runner, job.ID, domain.JobFailed)
+		assert.Equal(t, "no_cannon_available", job.ErrorCode)
+		assert.Equal(t, domain.ErrNoCannonAvailable.Error(), job.Error)
+	})
+
+	t.Run("deletes finished jobs once expired", func(t *testing.T) {
+		now := time.Now()
+		store := mocks.NewJobStoreMock(
+			&domain.Job{ID: "expired", Status: domain.JobSucceeded, UpdatedAt: now.Add(-2 * time.Hour)},
+			&domain.Job{ID: "failed", Status: domain.JobFailed, UpdatedAt: now.Add(-2 * time.Hour)},
+			&domain.Job{ID: "recent", Status: domain.JobSucceeded, UpdatedAt: now.Add(-time.Minute)},
+			&domain.Job{ID: "pending", Status: domain.JobPending, Radar: radar, UpdatedAt: now.Add(-2 * time.Hour)},
+		)
+		runner := NewJobRunner(endorService, store, 1, 10, WithJobTTL(time.Hour))
+
+		deleted, err := runner.Sweep(now)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+		_, err = runner.Get("expired")
+		assert.ErrorIs(t, err, domain.ErrJobNotFound)
+		_, err = runner.Get("recent")
+		assert.NoError(t, err)
+		_, err = runner.Get("pending")
+		assert.NoError(t, err)
+
+		deleted, err = NewJobRunner(endorService, store, 1, 10, WithJobTTL(0)).Sweep(now.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+)
+
+// leaseManager reserves ion cannons so two concurrent attacks never fire the same one.
+// A cannon stays reserved until its fire command completes. Cannons are reserved by ID, as the client
+// of a cannon can be replaced by a synchronisation of the fleet while an attack is firing it.
+type leaseManager struct {
+	mu       sync.Mutex
+	leased   map[string]struct{}
+	released chan struct{} // closed and replaced every time a cannon is released
+}
+
+func newLeaseManager() *leaseManager {
+	return &leaseManager{
+		leased:   map[string]struct{}{},
+		released: make(chan struct{}),
+	}
+}
+
+// acquire reserves the first ion cannon of the list that is not reserved by another attack.
+// It returns its index, or -1 if all of them are reserved, and a channel closed on the next release
+// so callers can queue for a cannon without missing a release.
+func (l *leaseManager) acquire(ionCannons []adapters.IonCannon) (int, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, c := range ionCannons {
+		if _, ok := l.leased[c.ID()]; !ok {
+			l.leased[c.ID()] = struct{}{}
+			return i, l.released
+		}
+	}
+	return -1, l.released
+}
+
+// isLeased returns true if the ion cannon is reserved by an attack.
+func (l *leaseManager) isLeased(ionCannon adapters.IonCannon) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.leased[ionCannon.ID()]
+	return ok
+}
+
+// release frees the ion cannon and wakes up the attacks waiting for one.
+func (l *leaseManager) release(ionCannon adapters.IonCannon) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.leased, ionCannon.ID())
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// waitReleased blocks until the ion cannon with the ID is not reserved by any attack.
+func (l *leaseManager) waitReleased(id string) {
+	for {
+		l.mu.Lock()
+		_, ok := l.leased[id]
+		released := l.released
+		l.mu.Unlock()
+
+		if !ok {
+			return
+		}
+		<-released
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaseManager(t *testing.T) {
+	leases := newLeaseManager()
+	cannon := &mocks.IonCannonClientMock{CannonID: "cannon-1"}
+	// The client of the same cannon after a synchronisation of the fleet
+	replaced := &mocks.IonCannonClientMock{CannonID: "cannon-1"}
+	other := &mocks.IonCannonClientMock{CannonID: "cannon-2"}
+
+	reserved, released := leases.acquire([]adapters.IonCannon{cannon})
+	assert.Equal(t, 0, reserved)
+	assert.True(t, leases.isLeased(replaced))
+
+	reserved, _ = leases.acquire([]adapters.IonCannon{replaced, other})
+	assert.Equal(t, 1, reserved)
+	reserved, _ = leases.acquire([]adapters.IonCannon{replaced})
+	assert.Equal(t, -1, reserved)
+
+	leases.release(cannon)
+	assert.False(t, leases.isLeased(replaced))
+	select {
+	case <-released:
+	default:
+		t.Error("the release did not wake up the attacks waiting for a cannon")
+	}
+
+	reserved, _ = leases.acquire([]adapters.IonCannon{replaced})
+	assert.Equal(t, 0, reserved)
+}
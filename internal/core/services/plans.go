@@ -0,0 +1,118 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultPlanTTL is the time a stored attack plan can be confirmed before it expires.
+const DefaultPlanTTL = 30 * time.Second
+
+var (
+	ErrPlanNotFound        = errors.New("attack plan not found")
+	ErrPlanExpired         = errors.New("attack plan expired")
+	ErrPlanAlreadyExecuted = errors.New("attack plan already executed")
+)
+
+type planState int
+
+const (
+	planPending planState = iota
+	planExecuting
+	planExecuted
+)
+
+// storedPlan is an attack plan kept server-side until it is confirmed or expires.
+type storedPlan struct {
+	*plannedAttack
+	state planState
+}
+
+// planStore keeps the attack plans waiting for confirmation.
+type planStore struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	plans map[string]*storedPlan
+}
+
+func newPlanStore(ttl time.Duration) *planStore {
+	return &planStore{
+		ttl:   ttl,
+		plans: map[string]*storedPlan{},
+	}
+}
+
+// add assigns an ID and an expiry to the plan and stores it.
+func (s *planStore) add(p *plannedAttack) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	p.plan.ID = id
+	p.plan.ExpiresAt = now.Add(s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired plans are removed lazily every time a new plan is stored.
+	for id, stored := range s.plans {
+		if stored.state != planExecuting && now.After(stored.plan.ExpiresAt) {
+			delete(s.plans, id)
+		}
+	}
+	s.plans[p.plan.ID] = &storedPlan{plannedAttack: p}
+
+	return nil
+}
+
+// acquire marks the plan as being executed so no other caller can fire it.
+func (s *planStore) acquire(id string) (*storedPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.plans[id]
+	if !ok {
+		return nil, ErrPlanNotFound
+	}
+
+	if p.state != planPending {
+		return nil, ErrPlanAlreadyExecuted
+	}
+
+	if time.Now().After(p.plan.ExpiresAt) {
+		delete(s.plans, id)
+		return nil, ErrPlanExpired
+	}
+
+	p.state = planExecuting
+	return p, nil
+}
+
+// release returns a plan that was not fired to the pending state so it can be confirmed again.
+func (s *planStore) release(p *storedPlan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.state = planPending
+}
+
+// done marks the plan as fired. It will never be fired again.
+func (s *planStore) done(p *storedPlan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.state = planExecuted
+}
+
+// newID generates a random identifier for plans and jobs.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
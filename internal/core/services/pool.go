@@ -0,0 +1,62 @@
+package services
+
+import "sync/atomic"
+
+// DefaultMaxConcurrency defines the default maximum number of goroutines to execute concurrently.
+const DefaultMaxConcurrency = 1000
+
+// WorkerPool is a bounded pool used to control the total number of goroutines executed.
+// For production we should have a hard limit on the number of goroutines we want in a way
+// developers should not need to think much about it to avoid losing control over how
+// many goroutines are executed in parallel.
+type WorkerPool struct {
+	semaphore chan struct{}
+	saturated uint64 // number of tasks that had to wait for a free worker
+}
+
+// PoolStats reports the usage of a WorkerPool.
+type PoolStats struct {
+	Capacity   int
+	InUse      int
+	Saturation float64 // InUse / Capacity
+	Saturated  uint64  // number of tasks that had to wait for a free worker
+}
+
+// NewWorkerPool creates a pool that runs at most size goroutines concurrently.
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		size = DefaultMaxConcurrency
+	}
+	return &WorkerPool{
+		semaphore: make(chan struct{}, size),
+	}
+}
+
+// Go runs fn in a new goroutine, blocking until a worker is free.
+// It returns true if the pool was saturated and the caller had to wait.
+func (p *WorkerPool) Go(fn func()) (waited bool) {
+	select {
+	case p.semaphore <- struct{}{}:
+	default:
+		atomic.AddUint64(&p.saturated, 1)
+		waited = true
+		p.semaphore <- struct{}{}
+	}
+
+	go func() {
+		defer func() { <-p.semaphore }()
+		fn()
+	}()
+	return waited
+}
+
+// Stats returns the current usage of the pool.
+func (p *WorkerPool) Stats() PoolStats {
+	inUse := len(p.semaphore)
+	return PoolStats{
+		Capacity:   cap(p.semaphore),
+		InUse:      inUse,
+		Saturation: float64(inUse) / float64(cap(p.semaphore)),
+		Saturated:  atomic.LoadUint64(&p.saturated),
+	}
+}
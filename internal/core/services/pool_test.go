@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(1)
+	other := NewWorkerPool(1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	waited := pool.Go(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	assert.False(t, waited)
+	assert.Equal(t, PoolStats{Capacity: 1, InUse: 1, Saturation: 1}, pool.Stats())
+
+	// Pools of different instances do not interfere with each other
+	assert.Equal(t, PoolStats{Capacity: 1}, other.Stats())
+
+	done := make(chan bool)
+	go func() {
+		done <- pool.Go(func() {})
+	}()
+	assert.Eventually(t, func() bool { return pool.Stats().Saturated == 1 }, time.Second, time.Millisecond)
+	close(release)
+
+	assert.True(t, <-done)
+}
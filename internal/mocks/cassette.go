@@ -0,0 +1,200 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// RecordCassettesEnv is the environment variable enabling the record mode of the cassettes, e.g.
+// RECORD_CASSETTES=1 go test ./...
+const RecordCassettesEnv = "RECORD_CASSETTES"
+
+// Cassette is a transport recording the exchanges of an HTTP client with an ion cannon in a fixture file,
+// and replaying them in later runs without the ion cannon. Use one cassette per ion cannon:
+//
+//	cassette := mocks.NewCassette(t, "testdata/sim-ion-cannon-1.json")
+//	client := ionCannonClient.NewIonCannonClient(url, ionCannonClient.WithTransportMiddleware(cassette.Wrap))
+//
+// In replay mode, the default, every request is answered with the first unused interaction with the same method,
+// path and body (JSON bodies are compared by value), and fails the test if there is none. In record mode,
+// enabled with RECORD_CASSETTES=1, requests are sent to the ion cannon and the file is written when the test ends.
+//
+// A cassette is only as faithful as the ion cannon it was recorded from: the fixtures of the repository are
+// recorded from the simulator, and prefixed with "sim-", so they do not prove the contract of the real ion cannons.
+type Cassette struct {
+	t      testing.TB
+	path   string
+	record bool
+	next   http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// Interaction is a request to an ion cannon and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"` // path and query of the URL
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode  int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// NewCassette loads the cassette in replay mode, or creates an empty one in record mode.
+func NewCassette(t testing.TB, path string) *Cassette {
+	t.Helper()
+	record, _ := strconv.ParseBool(os.Getenv(RecordCassettesEnv))
+	c := &Cassette{t: t, path: path, record: record}
+
+	if record {
+		t.Cleanup(c.save)
+		return c
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette %s: %v, record it with %s=1", path, err, RecordCassettesEnv)
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		t.Fatalf("cassette %s: %v", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c
+}
+
+// Wrap returns the cassette as the transport of a client, recording the requests sent with next in record mode.
+func (c *Cassette) Wrap(next http.RoundTripper) http.RoundTripper {
+	c.next = next
+	return c
+}
+
+// RoundTrip records or replays the request.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := RecordedRequest{Method: req.Method, Path: req.URL.RequestURI(), Body: string(body)}
+
+	if c.record {
+		return c.recordRoundTrip(req, recorded)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.interactions {
+		if !c.used[i] && matches(interaction.Request, recorded) {
+			c.used[i] = true
+			return interaction.Response.toHTTP(req), nil
+		}
+	}
+	err := fmt.Errorf("cassette %s: no recorded interaction for %s %s %s", c.path, recorded.Method, recorded.Path, recorded.Body)
+	c.t.Error(err)
+	return nil, err
+}
+
+func (c *Cassette) recordRoundTrip(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	next := c.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        string(body),
+		},
+	})
+	return resp, nil
+}
+
+// save writes the recorded interactions to the file of the cassette.
+func (c *Cassette) save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		c.t.Errorf("cassette %s: %v", c.path, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		c.t.Errorf("cassette %s: %v", c.path, err)
+		return
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		c.t.Errorf("cassette %s: %v", c.path, err)
+	}
+}
+
+// matches returns true if the request matches the recorded one. JSON bodies are compared by value.
+func matches(recorded RecordedRequest, req RecordedRequest) bool {
+	if recorded.Method != req.Method || recorded.Path != req.Path {
+		return false
+	}
+	if recorded.Body == req.Body {
+		return true
+	}
+	var a, b interface{}
+	if json.Unmarshal([]byte(recorded.Body), &a) != nil || json.Unmarshal([]byte(req.Body), &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toHTTP returns the recorded response as the response of the request.
+func (r RecordedResponse) toHTTP(req *http.Request) *http.Response {
+	header := http.Header{}
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
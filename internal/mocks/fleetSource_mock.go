@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+type FleetSourceMock struct {
+	mu          sync.Mutex
+	Definitions []*domain.CannonDefinition
+	Err         error
+}
+
+func (m *FleetSourceMock) Load() ([]*domain.CannonDefinition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.Definitions, m.Err
+}
+
+func (m *FleetSourceMock) Set(definitions []*domain.CannonDefinition, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Definitions = definitions
+	m.Err = err
+}
@@ -1,34 +1,61 @@
 package mocks
 
 import (
+	"context"
+	"sync"
+
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 )
 
 type IonCannonClientMock struct {
-	CheckStatusFunc     func() (*domain.IonCannon, error)
+	mu                  sync.Mutex
+	CannonID            string
+	CheckStatusFunc     func(ctx context.Context) (*domain.IonCannon, error)
 	CheckStatusCallData []struct{}
-	FireCommandFunc     func(int, int, int) (int, int, error)
+	FireCommandFunc     func(ctx context.Context, targetX int, targetY int, enemies int) (int, int, error)
 	FireCommandCallData []struct{ TargetX, TargetY, Enemies int }
+	Closed              bool
+}
+
+func (m *IonCannonClientMock) ID() string {
+	return m.CannonID
 }
 
-func (m *IonCannonClientMock) CheckStatus() (*domain.IonCannon, error) {
+func (m *IonCannonClientMock) CheckStatus(ctx context.Context) (*domain.IonCannon, error) {
 	callData := struct{}{}
+	m.mu.Lock()
 	m.CheckStatusCallData = append(m.CheckStatusCallData, callData)
+	m.mu.Unlock()
 
 	if m.CheckStatusFunc != nil {
-		return m.CheckStatusFunc()
+		return m.CheckStatusFunc(ctx)
 	}
 
 	return nil, nil
 }
 
-func (m *IonCannonClientMock) FireCommand(targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
+func (m *IonCannonClientMock) FireCommand(ctx context.Context, targetX int, targetY int, enemies int) (casualties int, generation int, err error) {
 	callData := struct{ TargetX, TargetY, Enemies int }{targetX, targetY, enemies}
+	m.mu.Lock()
 	m.FireCommandCallData = append(m.FireCommandCallData, callData)
+	m.mu.Unlock()
 
 	if m.FireCommandFunc != nil {
-		return m.FireCommandFunc(targetX, targetY, enemies)
+		return m.FireCommandFunc(ctx, targetX, targetY, enemies)
 	}
 
 	return 0, 0, nil
 }
+
+func (m *IonCannonClientMock) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Closed = true
+	return nil
+}
+
+func (m *IonCannonClientMock) IsClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Closed
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonGrpc/pb"
+)
+
+// IonCannonGrpcServerMock is an in-process Ion Cannon exposing the gRPC API.
+type IonCannonGrpcServerMock struct {
+	pb.UnimplementedIonCannonServer
+	StatusFunc func(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error)
+	FireFunc   func(ctx context.Context, req *pb.FireRequest) (*pb.FireResponse, error)
+}
+
+func (m *IonCannonGrpcServerMock) Status(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+	if m.StatusFunc != nil {
+		return m.StatusFunc(ctx, req)
+	}
+
+	return &pb.StatusResponse{}, nil
+}
+
+func (m *IonCannonGrpcServerMock) Fire(ctx context.Context, req *pb.FireRequest) (*pb.FireResponse, error) {
+	if m.FireFunc != nil {
+		return m.FireFunc(ctx, req)
+	}
+
+	return &pb.FireResponse{}, nil
+}
+
+// Serve starts the server on an in-memory listener. It returns the dial option to connect to it,
+// with a passthrough target such as "passthrough:///ion-cannon", and the function to stop it.
+func (m *IonCannonGrpcServerMock) Serve() (grpc.DialOption, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterIonCannonServer(srv, m)
+	go srv.Serve(lis)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	return dialer, srv.Stop
+}
@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// JobStoreMock is an in-memory job store.
+type JobStoreMock struct {
+	mu   sync.Mutex
+	Jobs map[string]domain.Job
+}
+
+func NewJobStoreMock(jobs ...*domain.Job) *JobStoreMock {
+	m := &JobStoreMock{Jobs: map[string]domain.Job{}}
+	for _, job := range jobs {
+		m.Jobs[job.ID] = *job
+	}
+	return m
+}
+
+func (m *JobStoreMock) Save(job *domain.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Jobs[job.ID] = *job
+	return nil
+}
+
+func (m *JobStoreMock) Get(id string) (*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.Jobs[id]
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *JobStoreMock) List() ([]*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := []*domain.Job{}
+	for _, job := range m.Jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+func (m *JobStoreMock) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.Jobs[id]; !ok {
+		return domain.ErrJobNotFound
+	}
+	delete(m.Jobs, id)
+	return nil
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// casualtyModelFile is the JSON representation of the casualty model, e.g:
+//
+//	{"killRate": {"soldier": 1, "mech": 0.5}, "generationFactor": 0.1, "deviationThreshold": 0.25}
+type casualtyModelFile struct {
+	KillRate           map[string]float64 `json:"killRate"`
+	GenerationFactor   *float64           `json:"generationFactor"`
+	DeviationThreshold *float64           `json:"deviationThreshold"`
+}
+
+// loadCasualtyModel reads the casualty model from a JSON file.
+// Values not present in the file keep the defaults of domain.DefaultCasualtyModel.
+func loadCasualtyModel(path string) (domain.CasualtyModel, error) {
+	model := domain.DefaultCasualtyModel()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model, fmt.Errorf("failed to read casualty model: %w", err)
+	}
+
+	var file casualtyModelFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return model, fmt.Errorf("failed to decode casualty model: %w", err)
+	}
+
+	for name, rate := range file.KillRate {
+		enemyType, err := domain.ParseStringToEnemyType(name)
+		if err != nil {
+			return model, fmt.Errorf("invalid casualty model: %w", err)
+		}
+		if rate < 0 || rate > 1 {
+			return model, fmt.Errorf("invalid casualty model: kill rate of %s must be between 0 and 1", name)
+		}
+		model.KillRate[enemyType] = rate
+	}
+	if file.GenerationFactor != nil {
+		model.GenerationFactor = *file.GenerationFactor
+	}
+	if file.DeviationThreshold != nil {
+		if *file.DeviationThreshold < 0 {
+			return model, fmt.Errorf("invalid casualty model: deviation threshold must be positive")
+		}
+		model.DeviationThreshold = *file.DeviationThreshold
+	}
+
+	return model, nil
+}
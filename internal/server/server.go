@@ -2,19 +2,29 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/fleetFile"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/handler"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonClient"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonGrpc"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/jobStore"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/logger"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/signature"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/tlsconfig"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
 	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/services"
 
 	"github.com/go-playground/validator/v10"
@@ -24,9 +34,13 @@ import (
 
 // App represents the Endor service application.
 type App struct {
-	logger *zap.SugaredLogger
-	svc    *services.EndorService
-	srv    adapters.ServerHTTP
+	logger        *zap.SugaredLogger
+	svc           *services.EndorService
+	jobs          *services.JobRunner
+	discovery     *services.FleetDiscovery
+	srv           adapters.ServerHTTP
+	adminAddr     string
+	shutdownDelay time.Duration
 }
 
 // Initialize initializes the Endor service application.
@@ -47,15 +61,227 @@ func (a *App) Initialize() error {
 	}
 	a.logger = logger.NewLogger(logLevel, false)
 
-	// TODO: Create instances of IonCannonClient from configuration file
-	ionCannon1 := ionCannonClient.NewIonCannonClient(os.Getenv("ION_CANNON_URL1"))
-	ionCannon2 := ionCannonClient.NewIonCannonClient(os.Getenv("ION_CANNON_URL2"))
-	ionCannon3 := ionCannonClient.NewIonCannonClient(os.Getenv("ION_CANNON_URL3"))
-	ionCannons := []adapters.IonCannon{ionCannon1, ionCannon2, ionCannon3}
+	clientOpts, err := ionCannonClientOptions()
+	if err != nil {
+		return err
+	}
+	grpcOpts, err := ionCannonGrpcOptions()
+	if err != nil {
+		return err
+	}
+	defaultTLS, err := ionCannonTLS()
+	if err != nil {
+		return err
+	}
+	// The protocol of every ion cannon is selected by the scheme of its URL
+	newIonCannonClient := func(d *domain.CannonDefinition) (adapters.IonCannon, error) {
+		cannonTLS := d.TLS
+		if cannonTLS == nil {
+			cannonTLS = defaultTLS
+		} else if strings.HasPrefix(d.URL, "http://") {
+			return nil, fmt.Errorf("tls requires an https or grpc URL: %s", d.URL)
+		}
+		var tlsConfig *tls.Config
+		if cannonTLS != nil && !strings.HasPrefix(d.URL, "http://") {
+			u, err := url.Parse(d.URL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ion cannon URL %s: %w", d.URL, err)
+			}
+			if tlsConfig, err = tlsconfig.NewClientConfig(tlsconfig.Files(*cannonTLS), u.Hostname()); err != nil {
+				return nil, err
+			}
+		}
+
+		var secret []byte
+		if d.Secret != nil {
+			var err error
+			if secret, err = signature.LoadSecret(d.Secret.File, d.Secret.Env); err != nil {
+				return nil, err
+			}
+		}
+
+		if strings.HasPrefix(d.URL, "grpc://") {
+			if secret != nil {
+				return nil, fmt.Errorf("signed fire commands are only supported by http and https cannons: %s", d.URL)
+			}
+			opts := append([]ionCannonGrpc.Option{ionCannonGrpc.WithID(d.ID)}, grpcOpts...)
+			if tlsConfig != nil {
+				opts = append(opts, ionCannonGrpc.WithTLSConfig(tlsConfig))
+			}
+			return ionCannonGrpc.NewIonCannonGrpcClient(strings.TrimPrefix(d.URL, "grpc://"), opts...)
+		}
+		opts := append([]ionCannonClient.Option{ionCannonClient.WithID(d.ID)}, clientOpts...)
+		if tlsConfig != nil {
+			opts = append(opts, ionCannonClient.WithTLSConfig(tlsConfig))
+		}
+		if secret != nil {
+			opts = append(opts, ionCannonClient.WithSigningSecret(secret))
+		}
+		return ionCannonClient.NewIonCannonClient(d.URL, opts...), nil
+	}
+
+	// The fleet can be changed at runtime with the admin endpoints and the fleet file.
+	fleet := services.NewFleet(newIonCannonClient)
+	for _, n := range []string{"1", "2", "3"} {
+		name := "ION_CANNON_URL" + n
+		url := os.Getenv(name)
+		if url == "" {
+			continue
+		}
+		// The fire commands of the cannon are signed if it has a secret
+		definition := &domain.CannonDefinition{ID: url, URL: url}
+		if file := os.Getenv("ION_CANNON_SECRET_FILE" + n); file != "" {
+			definition.Secret = &domain.CannonSecret{File: file}
+		} else if os.Getenv("ION_CANNON_SECRET"+n) != "" {
+			definition.Secret = &domain.CannonSecret{Env: "ION_CANNON_SECRET" + n}
+		}
+		client, err := newIonCannonClient(definition)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if err := fleet.Register(url, url, client); err != nil {
+			return err
+		}
+	}
+	if path := os.Getenv("FLEET_FILE"); path != "" {
+		source, err := fleetFile.NewFileFleetSource(path)
+		if err != nil {
+			return err
+		}
+		interval := services.DefaultDiscoveryInterval
+		if i := os.Getenv("FLEET_FILE_POLL_INTERVAL"); i != "" {
+			interval, err = time.ParseDuration(i)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("invalid FLEET_FILE_POLL_INTERVAL: %s", i)
+			}
+		}
+		a.discovery = services.NewFleetDiscovery(fleet, source, path, interval, a.logger)
+		if err := a.discovery.Start(); err != nil {
+			return err
+		}
+	}
+
+	opts := []services.Option{services.WithLogger(a.logger), services.WithFleet(fleet)}
+	if n := os.Getenv("MAX_CONCURRENCY"); n != "" {
+		maxConcurrency, err := strconv.Atoi(n)
+		if err != nil || maxConcurrency <= 0 {
+			return fmt.Errorf("invalid MAX_CONCURRENCY: %s", n)
+		}
+		opts = append(opts, services.WithMaxConcurrency(maxConcurrency))
+	}
+	if ttl := os.Getenv("PLAN_TTL"); ttl != "" {
+		planTTL, err := time.ParseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("invalid PLAN_TTL: %w", err)
+		}
+		opts = append(opts, services.WithPlanTTL(planTTL))
+	}
+
+	if t := os.Getenv("STATUS_TIMEOUT"); t != "" {
+		statusTimeout, err := time.ParseDuration(t)
+		if err != nil || statusTimeout <= 0 {
+			return fmt.Errorf("invalid STATUS_TIMEOUT: %s", t)
+		}
+		opts = append(opts, services.WithStatusTimeout(statusTimeout))
+	}
+	if early := os.Getenv("EARLY_SELECTION"); early != "" {
+		earlySelection, err := strconv.ParseBool(early)
+		if err != nil {
+			return fmt.Errorf("invalid EARLY_SELECTION: %s", early)
+		}
+		opts = append(opts, services.WithEarlySelection(earlySelection))
+	}
+	if n := os.Getenv("READY_MIN_CANNONS"); n != "" {
+		minCannons, err := strconv.Atoi(n)
+		if err != nil || minCannons < 0 {
+			return fmt.Errorf("invalid READY_MIN_CANNONS: %s", n)
+		}
+		opts = append(opts, services.WithMinReadyCannons(minCannons))
+	}
+
+	if path := os.Getenv("CASUALTY_MODEL_FILE"); path != "" {
+		model, err := loadCasualtyModel(path)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, services.WithCasualtyModel(model))
+	}
+
+	a.svc = services.NewEndorService(nil, opts...)
+
+	// Asynchronous attack jobs are persisted in a local directory to survive restarts
+	jobsDir := os.Getenv("JOBS_DIR")
+	if jobsDir == "" {
+		jobsDir = "data/jobs"
+	}
+	store, err := jobStore.NewFileJobStore(jobsDir, jobStore.WithLogger(a.logger))
+	if err != nil {
+		return err
+	}
+	jobWorkers := services.DefaultJobWorkers
+	if n := os.Getenv("JOB_WORKERS"); n != "" {
+		jobWorkers, err = strconv.Atoi(n)
+		if err != nil || jobWorkers <= 0 {
+			return fmt.Errorf("invalid JOB_WORKERS: %s", n)
+		}
+	}
+	jobOpts := []services.JobOption{services.WithJobErrorCode(handler.ErrorCode)}
+	if ttl := os.Getenv("JOB_TTL"); ttl != "" {
+		jobTTL, err := time.ParseDuration(ttl)
+		if err != nil || jobTTL < 0 {
+			return fmt.Errorf("invalid JOB_TTL: %s", ttl)
+		}
+		jobOpts = append(jobOpts, services.WithJobTTL(jobTTL))
+	}
+	a.jobs = services.NewJobRunner(a.svc, store, jobWorkers, services.DefaultJobQueueSize, jobOpts...)
+	if err := a.jobs.Start(); err != nil {
+		return err
+	}
+
+	if d := os.Getenv("SHUTDOWN_DELAY"); d != "" {
+		a.shutdownDelay, err = time.ParseDuration(d)
+		if err != nil || a.shutdownDelay < 0 {
+			return fmt.Errorf("invalid SHUTDOWN_DELAY: %s", d)
+		}
+	}
+
+	serverOpts := []handler.ServerOption{handler.WithJobRunner(a.jobs)}
+	if ttl := os.Getenv("IDEMPOTENCY_TTL"); ttl != "" {
+		idempotencyTTL, err := time.ParseDuration(ttl)
+		if err != nil {
+			return fmt.Errorf("invalid IDEMPOTENCY_TTL: %w", err)
+		}
+		serverOpts = append(serverOpts, handler.WithIdempotencyTTL(idempotencyTTL))
+	}
+	if n := os.Getenv("BATCH_CONCURRENCY"); n != "" {
+		batchConcurrency, err := strconv.Atoi(n)
+		if err != nil || batchConcurrency <= 0 {
+			return fmt.Errorf("invalid BATCH_CONCURRENCY: %s", n)
+		}
+		serverOpts = append(serverOpts, handler.WithBatchConcurrency(batchConcurrency))
+	}
+	if date := os.Getenv("UNVERSIONED_API_SUNSET"); date != "" {
+		sunset, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("invalid UNVERSIONED_API_SUNSET: %w", err)
+		}
+		serverOpts = append(serverOpts, handler.WithUnversionedSunset(sunset))
+	}
+
+	// The administration of the fleet has its own listener, only reachable from the host unless a token protects it
+	a.adminAddr = os.Getenv("ADMIN_ADDR")
+	if a.adminAddr == "" {
+		a.adminAddr = handler.DefaultAdminAddr
+	}
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken != "" {
+		serverOpts = append(serverOpts, handler.WithAdminToken(adminToken))
+	} else if !isLoopback(a.adminAddr) {
+		return fmt.Errorf("ADMIN_TOKEN is required to listen on ADMIN_ADDR %s", a.adminAddr)
+	}
 
-	a.svc = services.NewEndorService(a.logger, ionCannons)
 	validate := validator.New()
-	a.srv = handler.NewHTTPServer(a.svc, validate)
+	a.srv = handler.NewHTTPServer(a.svc, validate, serverOpts...)
 
 	return nil
 }
@@ -79,8 +305,13 @@ func (a *App) Run() {
 		// Block until a signal is received.
 		<-sig
 
+		// Report the service as not ready and keep serving while the load balancers stop sending traffic
+		a.svc.Shutdown()
+		time.Sleep(a.shutdownDelay)
+
 		// Shutdown signal with grace period of 30 seconds
-		shutdownCtx, _ := context.WithTimeout(serverCtx, 30*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(serverCtx, 30*time.Second)
+		defer cancel()
 
 		go func() {
 			<-shutdownCtx.Done()
@@ -94,9 +325,24 @@ func (a *App) Run() {
 		if err != nil {
 			a.logger.Errorln(err)
 		}
+		a.jobs.Stop()
+		if a.discovery != nil {
+			a.discovery.Stop()
+		}
+		// No attack is running anymore, the connections to the ion cannons can be closed
+		if err := a.svc.Fleet().Close(); err != nil {
+			a.logger.Errorln(err)
+		}
 		serverStopCtx()
 	}()
 
+	go func() {
+		err := a.srv.ListenAndServeAdmin(a.adminAddr)
+		if err != nil && err != http.ErrServerClosed {
+			a.logger.Errorln("Error starting admin server", "error", err)
+		}
+	}()
+
 	// Run the server
 	err := a.srv.ListenAndServe("")
 	if err != nil && err != http.ErrServerClosed {
@@ -107,6 +353,97 @@ func (a *App) Run() {
 	<-serverCtx.Done()
 }
 
+// isLoopback returns true if the address only listens on the loopback interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ionCannonGrpcOptions returns the options of the gRPC clients of the ion cannons set in the environment.
+func ionCannonGrpcOptions() ([]ionCannonGrpc.Option, error) {
+	var opts []ionCannonGrpc.Option
+	if t := os.Getenv("ION_CANNON_RESPONSE_TIMEOUT"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid ION_CANNON_RESPONSE_TIMEOUT: %s", t)
+		}
+		opts = append(opts, ionCannonGrpc.WithFireTimeout(timeout))
+	}
+	return opts, nil
+}
+
+// ionCannonClientOptions returns the options of the HTTP clients of the ion cannons set in the environment.
+func ionCannonClientOptions() ([]ionCannonClient.Option, error) {
+	var opts []ionCannonClient.Option
+	if t := os.Getenv("ION_CANNON_CONNECT_TIMEOUT"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid ION_CANNON_CONNECT_TIMEOUT: %s", t)
+		}
+		opts = append(opts, ionCannonClient.WithConnectTimeout(timeout))
+	}
+	if t := os.Getenv("ION_CANNON_RESPONSE_TIMEOUT"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid ION_CANNON_RESPONSE_TIMEOUT: %s", t)
+		}
+		opts = append(opts, ionCannonClient.WithResponseTimeout(timeout))
+	}
+	if n := os.Getenv("ION_CANNON_STATUS_RETRIES"); n != "" {
+		retries, err := strconv.Atoi(n)
+		if err != nil || retries < 0 {
+			return nil, fmt.Errorf("invalid ION_CANNON_STATUS_RETRIES: %s", n)
+		}
+		opts = append(opts, ionCannonClient.WithStatusRetries(retries, ionCannonClient.DefaultRetryBackoff))
+	}
+	if ua := os.Getenv("ION_CANNON_USER_AGENT"); ua != "" {
+		opts = append(opts, ionCannonClient.WithUserAgent(ua))
+	}
+	if stream := os.Getenv("ION_CANNON_STATUS_STREAM"); stream != "" {
+		enabled, err := strconv.ParseBool(stream)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ION_CANNON_STATUS_STREAM: %s", stream)
+		}
+		if enabled {
+			opts = append(opts, ionCannonClient.WithStatusStream())
+		}
+	}
+	if t := os.Getenv("ION_CANNON_STREAM_IDLE_TIMEOUT"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid ION_CANNON_STREAM_IDLE_TIMEOUT: %s", t)
+		}
+		opts = append(opts, ionCannonClient.WithStreamIdleTimeout(timeout))
+	}
+	return opts, nil
+}
+
+// ionCannonTLS returns the default TLS files of the ion cannons set in the environment, or nil.
+// They are used by the https and grpc ion cannons without their own TLS configuration.
+func ionCannonTLS() (*domain.CannonTLS, error) {
+	cannonTLS := &domain.CannonTLS{
+		CAFile:     os.Getenv("ION_CANNON_CA_FILE"),
+		CertFile:   os.Getenv("ION_CANNON_CERT_FILE"),
+		KeyFile:    os.Getenv("ION_CANNON_KEY_FILE"),
+		ServerName: os.Getenv("ION_CANNON_SERVER_NAME"),
+	}
+	if *cannonTLS == (domain.CannonTLS{}) {
+		return nil, nil
+	}
+	// The files are checked at startup, even if no ion cannon uses them yet
+	if _, err := tlsconfig.NewClientConfig(tlsconfig.Files(*cannonTLS), ""); err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration of the ion cannons: %w", err)
+	}
+	return cannonTLS, nil
+}
+
 // checkConfig checks if the required configuration is set.
 func checkConfig() error {
 	err := godotenv.Load()
@@ -123,10 +460,12 @@ func checkConfig() error {
 		{"ION_CANNON_URL2", os.Getenv("ION_CANNON_URL2")},
 		{"ION_CANNON_URL3", os.Getenv("ION_CANNON_URL3")},
 	}
+	// The ion cannons can be defined in a fleet file instead of the environment
+	useFleetFile := os.Getenv("FLEET_FILE") != ""
 
 	missingVariables := []string{}
 	for _, v := range requiredVariables {
-		if v.val == "" {
+		if v.val == "" && !(useFleetFile && strings.HasPrefix(v.name, "ION_CANNON_URL")) {
 			missingVariables = append(missingVariables, v.name)
 		}
 	}
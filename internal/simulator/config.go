@@ -0,0 +1,77 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/signature"
+)
+
+// LoadConfig reads the configuration of the simulator from the environment variables,
+// using the same CANNON_GEN variable as the ion cannon Docker image.
+func LoadConfig() (Config, error) {
+	cfg := Config{Generation: 1, KillRate: 1}
+
+	if gen := os.Getenv("CANNON_GEN"); gen != "" {
+		generation, err := strconv.Atoi(gen)
+		if err != nil || generation <= 0 {
+			return cfg, fmt.Errorf("invalid CANNON_GEN: %s", gen)
+		}
+		cfg.Generation = generation
+	}
+	cfg.FireTime = DefaultFireTime(cfg.Generation)
+
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"CANNON_FIRE_TIME", &cfg.FireTime},
+		{"CANNON_LATENCY", &cfg.Latency},
+		{"CANNON_LATENCY_JITTER", &cfg.LatencyJitter},
+	}
+	for _, d := range durations {
+		if v := os.Getenv(d.name); v != "" {
+			duration, err := time.ParseDuration(v)
+			if err != nil || duration < 0 {
+				return cfg, fmt.Errorf("invalid %s: %s", d.name, v)
+			}
+			*d.dst = duration
+		}
+	}
+
+	rates := []struct {
+		name string
+		dst  *float64
+	}{
+		{"CANNON_OVERLOAD_RATE", &cfg.OverloadRate},
+		{"CANNON_KILL_RATE", &cfg.KillRate},
+		{"CANNON_CASUALTY_VARIANCE", &cfg.CasualtyVariance},
+		{"CANNON_ERROR_RATE", &cfg.ErrorRate},
+	}
+	for _, r := range rates {
+		if v := os.Getenv(r.name); v != "" {
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return cfg, fmt.Errorf("invalid %s: %s, must be between 0 and 1", r.name, v)
+			}
+			*r.dst = rate
+		}
+	}
+
+	file := os.Getenv("CANNON_SECRET_FILE")
+	if file != "" || os.Getenv("CANNON_SECRET") != "" {
+		env := ""
+		if file == "" {
+			env = "CANNON_SECRET"
+		}
+		secret, err := signature.LoadSecret(file, env)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Secret = secret
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,265 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/common/signature"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+)
+
+// Config is the behaviour of the simulated ion cannon.
+type Config struct {
+	Generation       int
+	FireTime         time.Duration // time the cannon is unavailable after firing
+	OverloadRate     float64       // probability of an overload after firing, which doubles the recharge time
+	KillRate         float64       // fraction of the enemies destroyed by a shot
+	CasualtyVariance float64       // maximum relative random variation of the casualties
+	Latency          time.Duration // delay of every response
+	LatencyJitter    time.Duration // maximum random delay added to the latency
+	ErrorRate        float64       // probability of a 500 Internal Server Error response
+	Secret           []byte        // secret verifying the signature of the fire commands, if set
+}
+
+// DefaultFireTime returns the fire time of the ion cannons of the generation.
+func DefaultFireTime(generation int) time.Duration {
+	switch generation {
+	case 1:
+		return 3500 * time.Millisecond
+	case 2:
+		return 1500 * time.Millisecond
+	default:
+		return 2500 * time.Millisecond
+	}
+}
+
+// Simulator serves the /status and /fire endpoints of an ion cannon.
+type Simulator struct {
+	cfg Config
+	now func() time.Time
+
+	mu          sync.Mutex
+	rnd         *rand.Rand
+	availableAt time.Time
+	subscribers map[chan struct{}]bool // status streams notified when the availability changes
+}
+
+// Option configures the Simulator.
+type Option func(*Simulator)
+
+// WithClock sets the clock of the simulator, e.g. to test the recharge without waiting.
+func WithClock(now func() time.Time) Option {
+	return func(s *Simulator) {
+		s.now = now
+	}
+}
+
+// WithSeed sets the seed of the random errors, latencies, overloads and casualties.
+func WithSeed(seed int64) Option {
+	return func(s *Simulator) {
+		s.rnd = rand.New(rand.NewSource(seed))
+	}
+}
+
+// New creates a new available ion cannon simulator.
+func New(cfg Config, opts ...Option) *Simulator {
+	s := &Simulator{
+		cfg:         cfg,
+		now:         time.Now,
+		rnd:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		subscribers: map[chan struct{}]bool{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of the ion cannon API.
+func (s *Simulator) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(s.faults)
+	r.Get("/status", s.status)
+	r.Get("/status/stream", s.statusStream)
+
+	fire := http.Handler(http.HandlerFunc(s.fire))
+	if s.cfg.Secret != nil {
+		fire = signature.NewVerifier(s.cfg.Secret, signature.DefaultMaxSkew).Middleware(fire)
+	}
+	r.Method(http.MethodPost, "/fire", fire)
+	return r
+}
+
+type statusResponse struct {
+	Generation int  `json:"generation"`
+	Available  bool `json:"available"`
+}
+
+type fireRequest struct {
+	Target *struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"target"`
+	Enemies *int `json:"enemies"`
+}
+
+type fireResponse struct {
+	Casualties int `json:"casualties"`
+	Generation int `json:"generation"`
+}
+
+type errResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// status is the HTTP handler for the "GET /status" endpoint.
+func (s *Simulator) status(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	available := !s.now().Before(s.availableAt)
+	s.mu.Unlock()
+
+	render.JSON(w, r, &statusResponse{Generation: s.cfg.Generation, Available: available})
+}
+
+// statusStream is the HTTP handler for the "GET /status/stream" endpoint. It streams the status of the cannon
+// as Server-Sent Events when it connects and every time its availability changes.
+func (s *Simulator) statusStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusNotImplemented)
+		return
+	}
+
+	changed := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subscribers[changed] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, changed)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	send := func() {
+		s.mu.Lock()
+		data, _ := json.Marshal(&statusResponse{Generation: s.cfg.Generation, Available: !s.now().Before(s.availableAt)})
+		s.mu.Unlock()
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	send()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			send()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// notify notifies the status streams that the availability of the cannon changed.
+func (s *Simulator) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for changed := range s.subscribers {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// fire is the HTTP handler for the "POST /fire" endpoint. The cannon cannot fire while it is recharging.
+func (s *Simulator) fire(w http.ResponseWriter, r *http.Request) {
+	var data fireRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil || data.Target == nil || data.Enemies == nil || *data.Enemies < 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, &errResponse{Code: domain.CannonErrorBadTarget, Error: "invalid fire command"})
+		return
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	if now.Before(s.availableAt) {
+		s.mu.Unlock()
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, &errResponse{Code: domain.CannonErrorRecharging, Error: "ion cannon is recharging"})
+		return
+	}
+	recharge := s.cfg.FireTime
+	if s.rnd.Float64() < s.cfg.OverloadRate {
+		recharge *= 2
+	}
+	s.availableAt = now.Add(recharge)
+	casualties := s.casualties(*data.Enemies)
+	s.mu.Unlock()
+
+	// The streams are notified before the response, and again when the cannon is recharged
+	s.notify()
+	time.AfterFunc(recharge, s.notify)
+
+	render.JSON(w, r, &fireResponse{Casualties: casualties, Generation: s.cfg.Generation})
+}
+
+// casualties returns the enemies destroyed by a shot. It must be called with the lock held.
+func (s *Simulator) casualties(enemies int) int {
+	rate := s.cfg.KillRate * (1 + s.cfg.CasualtyVariance*(2*s.rnd.Float64()-1))
+	return int(math.Min(math.Max(math.Round(float64(enemies)*rate), 0), float64(enemies)))
+}
+
+// faults delays the responses and fails them at the configured rate.
+func (s *Simulator) faults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		delay := s.cfg.Latency
+		if s.cfg.LatencyJitter > 0 {
+			delay += time.Duration(s.rnd.Int63n(int64(s.cfg.LatencyJitter) + 1))
+		}
+		failed := s.rnd.Float64() < s.cfg.ErrorRate
+		s.mu.Unlock()
+
+		if err := sleep(r.Context(), delay); err != nil {
+			return
+		}
+		if failed {
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, &errResponse{Code: domain.CannonErrorInternal, Error: "ion cannon malfunction"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package simulator
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/adapters/ionCannonClient"
+	"github.com/hiring-seedtag/mihai-lupoiu-go-backend-test/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a manual clock for the recharge of the simulator.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSimulator(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	cfg := Config{Generation: 2, FireTime: DefaultFireTime(2), KillRate: 1}
+	server := httptest.NewServer(New(cfg, WithClock(clk.Now), WithSeed(1)).Handler())
+	defer server.Close()
+
+	// The simulator serves the contract of the ion cannons used by the service
+	client := ionCannonClient.NewIonCannonClient(server.URL, ionCannonClient.WithStatusRetries(0, 0))
+	status, err := client.CheckStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.IonCannon{Generation: 2, Available: true}, status)
+
+	casualties, generation, err := client.FireCommand(context.Background(), 0, 40, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, casualties)
+	assert.Equal(t, 2, generation)
+
+	// The cannon recharges after firing
+	status, err = client.CheckStatus(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status.Available)
+	_, _, err = client.FireCommand(context.Background(), 0, 40, 7)
+	assert.ErrorIs(t, err, domain.ErrCannonFireFailed)
+
+	clk.Advance(1500 * time.Millisecond)
+	status, err = client.CheckStatus(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Available)
+
+	resp, err := http.Post(server.URL+"/fire", "application/json", strings.NewReader(`{"enemies": 1}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSimulator_Faults(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	cfg := Config{Generation: 1, FireTime: time.Second, OverloadRate: 1, KillRate: 0.5, CasualtyVariance: 0.2}
+	server := httptest.NewServer(New(cfg, WithClock(clk.Now), WithSeed(1)).Handler())
+	defer server.Close()
+
+	// Casualties follow the kill rate within the variance
+	client := ionCannonClient.NewIonCannonClient(server.URL)
+	casualties, _, err := client.FireCommand(context.Background(), 0, 0, 100)
+	assert.NoError(t, err)
+	assert.InDelta(t, 50, casualties, 10)
+
+	// Overloads double the recharge time
+	clk.Advance(time.Second)
+	status, err := client.CheckStatus(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status.Available)
+
+	// Responses are delayed and failed at the configured rate
+	failing := httptest.NewServer(New(Config{Generation: 1, Latency: 20 * time.Millisecond, ErrorRate: 1}).Handler())
+	defer failing.Close()
+	client = ionCannonClient.NewIonCannonClient(failing.URL, ionCannonClient.WithStatusRetries(0, 0))
+	start := time.Now()
+	_, err = client.CheckStatus(context.Background())
+	assert.ErrorIs(t, err, domain.ErrCannonStatusFailed)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestSimulator_StatusStream(t *testing.T) {
+	server := httptest.NewServer(New(Config{Generation: 1, FireTime: 50 * time.Millisecond, KillRate: 1}).Handler())
+	defer server.Close()
+
+	// The status is streamed when the cannon fires and when it is recharged
+	resp, err := http.Get(server.URL + "/status/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				events <- strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	assert.JSONEq(t, `{"generation": 1, "available": true}`, <-events)
+
+	client := ionCannonClient.NewIonCannonClient(server.URL)
+	_, _, err = client.FireCommand(context.Background(), 0, 0, 1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"generation": 1, "available": false}`, <-events)
+	assert.JSONEq(t, `{"generation": 1, "available": true}`, <-events)
+}
+
+func TestSimulator_Signature(t *testing.T) {
+	server := httptest.NewServer(New(Config{Generation: 1, KillRate: 1, Secret: []byte("top-secret")}).Handler())
+	defer server.Close()
+
+	client := ionCannonClient.NewIonCannonClient(server.URL)
+	_, _, err := client.FireCommand(context.Background(), 0, 0, 1)
+	assert.ErrorIs(t, err, domain.ErrCannonFireFailed)
+
+	client = ionCannonClient.NewIonCannonClient(server.URL, ionCannonClient.WithSigningSecret([]byte("top-secret")))
+	_, _, err = client.FireCommand(context.Background(), 0, 0, 1)
+	assert.NoError(t, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("CANNON_GEN", "3")
+	t.Setenv("CANNON_ERROR_RATE", "0.1")
+	cfg, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Generation: 3, FireTime: 2500 * time.Millisecond, KillRate: 1, ErrorRate: 0.1}, cfg)
+
+	t.Setenv("CANNON_ERROR_RATE", "2")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}